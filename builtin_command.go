@@ -1,6 +1,8 @@
 package exoskeleton
 
 import (
+	"context"
+
 	"github.com/square/exoskeleton/v2/pkg/shellcomp"
 )
 
@@ -18,9 +20,16 @@ func (c *builtinCommand) Summary() (string, error) { return c.definition.Summary
 func (c *builtinCommand) Help() (string, error)    { return c.definition.Help, nil }
 
 func (c *builtinCommand) Exec(e *Entrypoint, args, env []string) error {
+	return c.ExecContext(context.Background(), e, args, env)
+}
+
+func (c *builtinCommand) ExecContext(ctx context.Context, e *Entrypoint, args, env []string) error {
 	if len(c.subcommands) > 0 {
 		return e.printModuleHelp(c, args)
 	}
+	if c.definition.ExecContext != nil {
+		return c.definition.ExecContext(ctx, e, args, env)
+	}
 	return c.definition.Exec(e, args, env)
 }
 
@@ -34,6 +43,10 @@ func (c *builtinCommand) Complete(e *Entrypoint, args, env []string) ([]string,
 	return []string{}, shellcomp.DirectiveNoFileComp, nil
 }
 
+func (c *builtinCommand) CompleteContext(_ context.Context, e *Entrypoint, args, env []string) ([]string, shellcomp.Directive, error) {
+	return c.Complete(e, args, env)
+}
+
 func (c *builtinCommand) DefaultSubcommand() Command {
 	if c.definition.DefaultCommand == "" {
 		return nil
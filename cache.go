@@ -1,8 +1,10 @@
 package exoskeleton
 
 import (
+	"context"
 	"encoding/json"
 	"os"
+	"strings"
 	"sync"
 	"time"
 
@@ -20,10 +22,33 @@ type Cache interface {
 	//
 	// The key identifies the operation (e.g., "summary", "describe-commands").
 	// Implementations typically use cmd.Path() combined with key to form a cache key,
-	// and may use the file's modification time for cache invalidation.
+	// and may use the file's modification time for cache invalidation. Note that
+	// subcommands invoked through their parent's executable share its path; Usage(cmd)
+	// tells them apart.
 	Fetch(cmd Command, key string, compute func() (string, error)) (string, error)
 }
 
+// ContextCache is implemented by Caches that accept a context.Context, which
+// is passed to compute(). FetchContext returns ctx.Err() if ctx is done before
+// a value is available.
+type ContextCache interface {
+	Cache
+	FetchContext(ctx context.Context, cmd Command, key string, compute func(context.Context) (string, error)) (string, error)
+}
+
+// fetch fetches a value from the cache, passing ctx along to caches that
+// implement ContextCache.
+func fetch(ctx context.Context, cache Cache, cmd Command, key string, compute func(context.Context) (string, error)) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	if cc, ok := cache.(ContextCache); ok {
+		return cc.FetchContext(ctx, cmd, key, compute)
+	}
+	return cache.Fetch(cmd, key, func() (string, error) { return compute(ctx) })
+}
+
 // nullCache is the default cache that performs no caching.
 // It simply invokes the compute function on every call.
 type nullCache struct{}
@@ -32,12 +57,20 @@ func (nullCache) Fetch(_ Command, _ string, compute func() (string, error)) (str
 	return compute()
 }
 
+func (nullCache) FetchContext(ctx context.Context, _ Command, _ string, compute func(context.Context) (string, error)) (string, error) {
+	return compute(ctx)
+}
+
 // FileCache is a file-backed cache with mtime-based invalidation and optional TTL expiration.
 // It persists cache entries to a JSON file and invalidates entries when the source file's
 // mtime changes or the TTL expires.
 //
 // FileCache is safe for concurrent use. It uses singleflight to deduplicate concurrent
 // calls with the same key.
+//
+// Cache hits and misses are logged (at the debug level) to the logger of the
+// Entrypoint that discovered the command (see WithLogger). Callers that share a
+// computation with a concurrent call aren't logged separately.
 type FileCache struct {
 	// Path is the location of the cache file (e.g., "~/.myapp/cache.json").
 	Path string
@@ -59,10 +92,10 @@ type fileCacheEntry struct {
 }
 
 func (c *FileCache) Fetch(cmd Command, key string, compute func() (string, error)) (string, error) {
-	cacheKey := key + ":" + cmd.Path()
+	cacheKey := cacheKey(cmd, key)
 
 	result, err, _ := c.sf.Do(cacheKey, func() (interface{}, error) {
-		return c.fetchOnce(cmd.Path(), cacheKey, compute)
+		return c.fetchOnce(cmd, key, cacheKey, compute)
 	})
 
 	if err != nil {
@@ -71,10 +104,57 @@ func (c *FileCache) Fetch(cmd Command, key string, compute func() (string, error
 	return result.(string), nil
 }
 
-func (c *FileCache) fetchOnce(path, cacheKey string, compute func() (string, error)) (string, error) {
+// FetchContext is like Fetch but stops waiting for a value when ctx is done.
+//
+// Concurrent calls with the same key share a single computation, so it isn't
+// canceled when ctx is done: it runs with a context that carries ctx's values
+// but not its cancellation, and its value is cached for the callers that are
+// still waiting (and for later calls).
+func (c *FileCache) FetchContext(ctx context.Context, cmd Command, key string, compute func(context.Context) (string, error)) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	cacheKey := cacheKey(cmd, key)
+	shared := context.WithoutCancel(ctx)
+
+	ch := c.sf.DoChan(cacheKey, func() (interface{}, error) {
+		return c.fetchOnce(cmd, key, cacheKey, func() (string, error) { return compute(shared) })
+	})
+
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case result := <-ch:
+		if result.Err != nil {
+			return "", result.Err
+		}
+		return result.Val.(string), nil
+	}
+}
+
+// argumentsReporter is implemented by commands that are invoked through an
+// executable with arguments that identify them (see cacheKey).
+type argumentsReporter interface {
+	arguments() []string
+}
+
+// cacheKey returns the key that FileCache stores cmd's value for key under.
+// Subcommands that are invoked through their parent's executable (like those
+// declared by --describe-commands or a manifest) share its path, so the
+// arguments that invoke them are included to tell them apart.
+func cacheKey(cmd Command, key string) string {
+	cacheKey := key + ":" + cmd.Path()
+	if a, ok := cmd.(argumentsReporter); ok && len(a.arguments()) > 0 {
+		cacheKey += " " + strings.Join(a.arguments(), " ")
+	}
+	return cacheKey
+}
+
+func (c *FileCache) fetchOnce(cmd Command, key, cacheKey string, compute func() (string, error)) (string, error) {
 	c.ensureLoaded()
 
-	modTime := c.modTime(path)
+	modTime := c.modTime(cmd.Path())
 	now := time.Now().Unix()
 
 	// Check cache
@@ -83,6 +163,7 @@ func (c *FileCache) fetchOnce(path, cacheKey string, compute func() (string, err
 	c.mu.RUnlock()
 
 	if ok && c.isValid(entry, modTime, now) {
+		loggerFor(cmd).Debug("cache hit", "key", key, "path", cmd.Path())
 		return entry.Value, nil
 	}
 
@@ -91,6 +172,7 @@ func (c *FileCache) fetchOnce(path, cacheKey string, compute func() (string, err
 	if err != nil {
 		return "", err
 	}
+	loggerFor(cmd).Debug("cache miss", "key", key, "path", cmd.Path())
 
 	// Update cache
 	c.mu.Lock()
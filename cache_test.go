@@ -1,6 +1,7 @@
 package exoskeleton
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 	"path/filepath"
@@ -340,3 +341,71 @@ func compute(value string) func() (string, error) {
 		return value, nil
 	}
 }
+
+func TestFileCacheFetchContext(t *testing.T) {
+	cmdFile, err := os.CreateTemp("", "cache-test-cmd")
+	require.NoError(t, err)
+	defer os.Remove(cmdFile.Name())
+	cmdFile.Close()
+
+	cache := &FileCache{Path: filepath.Join(t.TempDir(), "cache.json")}
+	cmd := &mockCommand{path: cmdFile.Name()}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = cache.FetchContext(ctx, cmd, "summary", func(ctx context.Context) (string, error) {
+		t.Error("should not compute a value after ctx is done")
+		return "", nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	result, err := cache.FetchContext(context.Background(), cmd, "summary", func(ctx context.Context) (string, error) {
+		return "SUMMARY VALUE", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "SUMMARY VALUE", result)
+}
+
+func TestFileCacheFetchContextIsNotCanceledByTheFirstCaller(t *testing.T) {
+	cmdFile, err := os.CreateTemp("", "cache-test-cmd")
+	require.NoError(t, err)
+	defer os.Remove(cmdFile.Name())
+	cmdFile.Close()
+
+	cache := &FileCache{Path: filepath.Join(t.TempDir(), "cache.json")}
+	cmd := &mockCommand{path: cmdFile.Name()}
+
+	started, proceed := make(chan struct{}), make(chan struct{})
+	compute := func(ctx context.Context) (string, error) {
+		close(started)
+		<-proceed
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		return "SUMMARY VALUE", nil
+	}
+
+	// The first caller gives up while the value is being computed...
+	first, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error)
+	go func() {
+		_, err := cache.FetchContext(first, cmd, "summary", compute)
+		firstErr <- err
+	}()
+	<-started
+
+	second := make(chan string)
+	go func() {
+		result, err := cache.FetchContext(context.Background(), cmd, "summary", compute)
+		assert.NoError(t, err)
+		second <- result
+	}()
+
+	cancel()
+	assert.ErrorIs(t, <-firstErr, context.Canceled)
+
+	// ...which doesn't cancel the computation that the second caller shares
+	close(proceed)
+	assert.Equal(t, "SUMMARY VALUE", <-second)
+}
@@ -0,0 +1,102 @@
+package exoskeleton
+
+import (
+	"io"
+	"os"
+)
+
+// NoColorEnvVar is the environment variable that turns off colors and other
+// styles in menus and help when it is set to any value but "" (see
+// https://no-color.org). It takes precedence over terminal detection but not
+// over WithColor(ColorAlways).
+const NoColorEnvVar = "NO_COLOR"
+
+// ColorMode determines whether menus and help are styled (see WithColor).
+type ColorMode int
+
+const (
+	// ColorAuto styles output when standard output is a terminal and
+	// NO_COLOR is not set.
+	ColorAuto ColorMode = iota
+
+	// ColorAlways styles output even when it is piped or redirected.
+	ColorAlways
+
+	// ColorNever never styles output.
+	ColorNever
+)
+
+// A Style is an ANSI escape sequence (like "\033[1m" for bold) that styles text.
+// The empty Style leaves text as it is.
+type Style string
+
+// styleReset is the escape sequence that ends a Style.
+const styleReset = "\033[0m"
+
+// Render returns text with the style applied.
+func (s Style) Render(text string) string {
+	if s == "" {
+		return text
+	}
+	return string(s) + text + styleReset
+}
+
+// Theme styles menus and help. Templates given to WithMenuTemplate can style
+// text with the Theme the Menu is executed with:
+//
+//	{{.Theme.Heading.Render "USAGE"}}
+//
+// The zero value (PlainTheme) doesn't style anything.
+type Theme struct {
+	// Heading styles section headings like "USAGE" and "COMMANDS".
+	Heading Style
+
+	// Command styles commands that users are invited to run.
+	Command Style
+
+	// Hint styles hints like "Run ... to print information on a specific command."
+	Hint Style
+}
+
+// DefaultTheme is the Theme used when none is supplied with WithTheme.
+var DefaultTheme = Theme{
+	Heading: "\033[1m",
+	Command: "\033[96m",
+}
+
+// PlainTheme doesn't style anything. It is used when colors are turned off.
+var PlainTheme = Theme{}
+
+// theme returns the Theme that menus and help are rendered with: PlainTheme if
+// output isn't to be styled (see WithColor).
+func (e *Entrypoint) theme() Theme {
+	if !e.colorEnabled() {
+		return PlainTheme
+	}
+	if e.customTheme != nil {
+		return *e.customTheme
+	}
+	return DefaultTheme
+}
+
+// colorEnabled returns true if menus and help should be styled.
+func (e *Entrypoint) colorEnabled() bool {
+	switch e.color {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	default:
+		return os.Getenv(NoColorEnvVar) == "" && isTerminal(e.Stdout())
+	}
+}
+
+// isTerminal returns true if w is a terminal (a character device).
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
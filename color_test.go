@@ -0,0 +1,76 @@
+package exoskeleton
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestColorEnabled(t *testing.T) {
+	t.Setenv(NoColorEnvVar, "")
+
+	var b bytes.Buffer
+	assert.False(t, (&Entrypoint{stdout: &b}).colorEnabled(), "should not style output that isn't to a terminal")
+	assert.True(t, (&Entrypoint{stdout: &b, color: ColorAlways}).colorEnabled())
+
+	f, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	require.NoError(t, err)
+	defer f.Close()
+	assert.False(t, (&Entrypoint{stdout: f}).colorEnabled(), "should not style output redirected to a file")
+
+	if tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0); err == nil {
+		defer tty.Close()
+		assert.True(t, (&Entrypoint{stdout: tty}).colorEnabled(), "should style output to a terminal")
+		assert.False(t, (&Entrypoint{stdout: tty, color: ColorNever}).colorEnabled())
+
+		t.Setenv(NoColorEnvVar, "1")
+		assert.False(t, (&Entrypoint{stdout: tty}).colorEnabled(), "should respect NO_COLOR")
+		assert.True(t, (&Entrypoint{stdout: tty, color: ColorAlways}).colorEnabled())
+	}
+}
+
+func TestTheme(t *testing.T) {
+	assert.Equal(t, Theme{}, (&Entrypoint{color: ColorNever}).theme())
+	assert.Equal(t, DefaultTheme, (&Entrypoint{color: ColorAlways}).theme())
+
+	custom := Theme{Heading: "\033[4m"}
+	assert.Equal(t, custom, (&Entrypoint{color: ColorAlways, customTheme: &custom}).theme())
+	assert.Equal(t, Theme{}, (&Entrypoint{color: ColorNever, customTheme: &custom}).theme())
+}
+
+func TestStyledHelpAndMenus(t *testing.T) {
+	help := "USAGE\n   e deploy\n\nEXAMPLES\n   e deploy web"
+	assert.Equal(t, help, formatHelp(help, Theme{}))
+	assert.Equal(t, "\033[4mUSAGE\033[0m\n   e deploy\n\n\033[4mEXAMPLES\033[0m\n   e deploy web", formatHelp(help, Theme{Heading: "\033[4m"}))
+
+	dir := t.TempDir()
+	writeScript(t, filepath.Join(dir, "deploy"), "#!/bin/sh\n# SUMMARY: Deploys a service\n")
+
+	var stdout bytes.Buffer
+	entrypoint, err := New([]string{dir}, WithName("e"), WithStdout(&stdout))
+	require.NoError(t, err)
+	require.NoError(t, entrypoint.Exec(entrypoint, nil, nil))
+	assert.NotContains(t, stdout.String(), "\033[", "should not style output that isn't to a terminal")
+
+	stdout.Reset()
+	entrypoint, err = New([]string{dir}, WithName("e"), WithStdout(&stdout), WithColor(ColorAlways), WithTheme(Theme{
+		Heading: "<h>",
+		Command: "<c>",
+		Hint:    "<i>",
+	}))
+	require.NoError(t, err)
+	require.NoError(t, entrypoint.Exec(entrypoint, nil, nil))
+	assert.Equal(t, `<h>USAGE`+styleReset+`
+   e <command> [<args>]
+
+<h>COMMANDS`+styleReset+`
+   deploy  Deploys a service
+
+<i>Run`+styleReset+` <c>e help <command>`+styleReset+` <i>to print information on a specific command.`+styleReset+`
+
+`, stdout.String())
+}
@@ -1,6 +1,8 @@
 package exoskeleton
 
 import (
+	"context"
+
 	"github.com/square/exoskeleton/v2/pkg/shellcomp"
 )
 
@@ -56,7 +58,9 @@ type Command interface {
 	// Returns an empty slice for leaf commands (commands without subcommands).
 	//
 	// Returns a CommandError if the command does not fulfill the contract
-	// for providing its subcommands.
+	// for providing its subcommands. Returns a PartialDiscoveryError, along
+	// with the subcommands that were discovered, if discovering some of its
+	// subcommands failed.
 	Subcommands() (Commands, error)
 
 	// DefaultSubcommand returns the default subcommand for this command, if one
@@ -64,3 +68,75 @@ type Command interface {
 	// not match any subcommand.
 	DefaultSubcommand() Command
 }
+
+// HiddenReporter is implemented by Commands that can be hidden. Hidden commands
+// are not listed in menus or suggested as completions, but they can still be run.
+type HiddenReporter interface {
+	Hidden() bool
+}
+
+// HeadingReporter is implemented by Commands that can declare the heading they
+// are listed under in menus. A declared heading takes precedence over the
+// MenuHeadingForFunc.
+type HeadingReporter interface {
+	// Heading returns the command's heading or "" if it doesn't declare one.
+	Heading() string
+}
+
+// DeprecationReporter is implemented by Commands that can be deprecated.
+// A warning is printed when a deprecated command is identified.
+type DeprecationReporter interface {
+	// Deprecated returns true and a message (which may be empty) explaining
+	// what to use instead if the command is deprecated.
+	Deprecated() (string, bool)
+}
+
+// ContextCommand is implemented by Commands whose Exec and Complete can be
+// canceled. Child processes they start are killed when the context is done.
+// Use ExecCommand and CompleteCommand to call these methods on any Command.
+type ContextCommand interface {
+	// ExecContext is like Exec but stops when ctx is done.
+	ExecContext(ctx context.Context, e *Entrypoint, args, env []string) error
+
+	// CompleteContext is like Complete but stops when ctx is done.
+	CompleteContext(ctx context.Context, e *Entrypoint, args, env []string) ([]string, shellcomp.Directive, error)
+}
+
+// ExecCommand executes cmd with ctx if it is a ContextCommand or else with Exec.
+// The Entrypoint's BeforeExec and AfterExec callbacks are invoked before and
+// after cmd is executed.
+func ExecCommand(ctx context.Context, cmd Command, e *Entrypoint, args, env []string) error {
+	if e != nil && len(e.afterExecCallbacks) > 0 {
+		ctx = context.WithValue(ctx, afterExecKey{}, true)
+	}
+	return e.execWithCallbacks(cmd, args, func() error {
+		if c, ok := cmd.(ContextCommand); ok {
+			return c.ExecContext(ctx, e, args, env)
+		}
+		return cmd.Exec(e, args, env)
+	})
+}
+
+// CompleteCommand asks cmd for completions with ctx if it is a ContextCommand
+// or else with Complete.
+func CompleteCommand(ctx context.Context, cmd Command, e *Entrypoint, args, env []string) ([]string, shellcomp.Directive, error) {
+	if c, ok := cmd.(ContextCommand); ok {
+		return c.CompleteContext(ctx, e, args, env)
+	}
+	return cmd.Complete(e, args, env)
+}
+
+// contextDiscoverer is implemented by Commands that can stop discovering their
+// subcommands when a context is done.
+type contextDiscoverer interface {
+	subcommandsContext(ctx context.Context) (Commands, error)
+}
+
+// subcommandsContext returns cmd's subcommands, stopping discovery when ctx is
+// done if cmd discovers its subcommands by executing a child process.
+func subcommandsContext(ctx context.Context, cmd Command) (Commands, error) {
+	if c, ok := cmd.(contextDiscoverer); ok {
+		return c.subcommandsContext(ctx)
+	}
+	return cmd.Subcommands()
+}
@@ -30,7 +30,9 @@ func (c Commands) Flatten() (Commands, []error) {
 
 // Expand returns a list of commands, recursively replacing modules
 // with their subcommands up to a given depth, along with any errors
-// returned by modules' Subcommands().
+// returned by modules' Subcommands(). Modules whose Subcommands()
+// returns a PartialDiscoveryError are expanded to the subcommands
+// that were discovered.
 func (c Commands) Expand(fops ...ExpandOption) (Commands, []error) {
 	o := &expandOptions{
 		depth:                  -1,
@@ -59,10 +61,19 @@ func WithoutExpandedModules() ExpandOption {
 
 func expand(c Commands, depth int, includeExpandedModules bool) (Commands, []error) {
 	return parallelMap(c, func(cmd Command) ([]Command, []error) {
+		errs := []error{}
+
+		subcmds, err := cmd.Subcommands()
+		if err != nil {
+			errs = append(errs, err)
+			if !isPartial(err) {
+				return []Command{cmd}, errs
+			}
+		}
+
 		// If this command has subcommands, recursively flatten them...
-		if subcmds, err := cmd.Subcommands(); err == nil && len(subcmds) > 0 && depth != 0 {
+		if len(subcmds) > 0 && depth != 0 {
 			cmds := []Command{}
-			errs := []error{}
 
 			if includeExpandedModules {
 				cmds = append(cmds, cmd)
@@ -73,10 +84,8 @@ func expand(c Commands, depth int, includeExpandedModules bool) (Commands, []err
 			errs = append(errs, ferrs...)
 
 			return cmds, errs
-		} else if err != nil {
-			return []Command{cmd}, []error{err}
 		} else {
-			return []Command{cmd}, []error{}
+			return []Command{cmd}, errs
 		}
 	})
 }
@@ -2,6 +2,7 @@ package exoskeleton
 
 import (
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -128,3 +129,39 @@ func namesOf(cmds Commands) string {
 	}
 	return strings.Join(result, "\n")
 }
+
+// Run with -race: lazily-discovered modules are discovered concurrently.
+func TestConcurrentDiscovery(t *testing.T) {
+	entrypoint, err := New([]string{fixtures})
+	assert.NoError(t, err)
+
+	var wg sync.WaitGroup
+	results := make([]string, 8)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			switch i % 3 {
+			case 0:
+				results[i], _ = MenuFor(entrypoint, &MenuOptions{Depth: -1})
+			case 1:
+				all, _ := entrypoint.cmds.Flatten()
+				results[i] = namesOf(all)
+			case 2:
+				entrypoint.suggestionsFor("helo")
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	all, _ := entrypoint.cmds.Flatten()
+	menu, _ := MenuFor(entrypoint, &MenuOptions{Depth: -1})
+	for i, result := range results {
+		switch i % 3 {
+		case 0:
+			assert.Equal(t, menu, result)
+		case 1:
+			assert.Equal(t, namesOf(all), result)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package exoskeleton
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// commentLeaderFor returns the string that begins a single-line comment in a
+// script, which is used to read its magic comments.
+//
+// It is inferred from the interpreter named in the script's shebang and, failing
+// that, from the script's extension. Overrides are keyed by interpreter name
+// (e.g. "node") or by extension including the leading dot (e.g. ".js") and
+// take precedence over the built-in languages: an override for a script's
+// extension is used even if the interpreter in its shebang is recognized.
+func commentLeaderFor(shebang, path string, overrides map[string]string) string {
+	interpreter := interpreterFromShebang(shebang)
+	ext := strings.ToLower(filepath.Ext(path))
+
+	if leader, ok := overrides[interpreter]; ok && interpreter != "" {
+		return leader
+	} else if leader, ok := overrides[ext]; ok && ext != "" {
+		return leader
+	} else if leader := commentLeaderForInterpreter(interpreter); leader != "" {
+		return leader
+	} else if leader := commentLeaderForExtension(ext); leader != "" {
+		return leader
+	}
+	return defaultCommentLeader
+}
+
+// interpreterFromShebang returns the name of the interpreter in a shebang line
+// like '#!/usr/bin/env node' or '#!/bin/bash -e', skipping over env and its flags.
+func interpreterFromShebang(shebang string) string {
+	if !strings.HasPrefix(shebang, "#!") {
+		return ""
+	}
+
+	fields := strings.Fields(strings.TrimPrefix(shebang, "#!"))
+	for len(fields) > 0 {
+		name := filepath.Base(fields[0])
+		if name != "env" && !strings.HasPrefix(name, "-") && !strings.Contains(name, "=") {
+			return name
+		}
+		fields = fields[1:]
+	}
+	return ""
+}
+
+// commentLeaderForInterpreter returns the string that begins a single-line
+// comment in the language run by the given interpreter, or "" if the
+// interpreter is not recognized.
+func commentLeaderForInterpreter(interpreter string) string {
+	switch interpreter {
+	case "sh", "bash", "zsh", "dash", "ksh", "fish", "python", "python2", "python3", "ruby", "perl", "Rscript", "pwsh", "awk", "make", "elixir", "tclsh":
+		return "#"
+	case "node", "nodejs", "deno", "bun", "ts-node", "tsx", "gorun", "kotlin", "scala", "groovy", "php", "dart":
+		return "//"
+	case "lua", "luajit", "runghc", "runhaskell":
+		return "--"
+	case "clojure", "clj", "bb", "sbcl", "racket", "guile", "emacs":
+		return ";"
+	default:
+		return ""
+	}
+}
+
+// commentLeaderForExtension returns the string that begins a single-line comment
+// in files with the given extension, or "" if the extension is not recognized.
+func commentLeaderForExtension(ext string) string {
+	switch strings.ToLower(ext) {
+	case ".sh", ".bash", ".zsh", ".py", ".rb", ".pl", ".r", ".ps1", ".exs", ".tcl":
+		return "#"
+	case ".js", ".mjs", ".cjs", ".ts", ".go", ".java", ".kt", ".kts", ".swift", ".scala", ".groovy", ".rs", ".c", ".cpp", ".cs", ".dart", ".php":
+		return "//"
+	case ".lua", ".sql", ".hs", ".ada", ".elm":
+		return "--"
+	case ".clj", ".cljs", ".cljc", ".edn", ".lisp", ".el", ".scm", ".rkt":
+		return ";"
+	case ".bat", ".cmd":
+		return "REM"
+	default:
+		return ""
+	}
+}
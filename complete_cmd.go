@@ -1,6 +1,7 @@
 package exoskeleton
 
 import (
+	"context"
 	"fmt"
 	"os"
 
@@ -31,48 +32,59 @@ COMPLETION DIRECTIVES
 
 // CompleteExec implements the 'complete' command.
 func CompleteExec(e *Entrypoint, args, env []string) error {
-	completions, directive, err := e.completionsFor(args, env, true)
+	return CompleteExecContext(context.Background(), e, args, env)
+}
+
+// CompleteExecContext implements the 'complete' command. Commands executed to
+// obtain completions are killed when ctx is done (e.g. when the shell gives up).
+func CompleteExecContext(ctx context.Context, e *Entrypoint, args, env []string) error {
+	completions, directive, err := e.completionsFor(ctx, args, env, true)
 
+	var debugErr error
 	if err != nil {
-		completionError(err.Error())
+		debugErr = completionError(e, err.Error())
 		// Keep going for multiple reasons:
 		// 1) There could be some valid completions even though there was an error
 		// 2) Even without completions, we need to print the directive
 	}
 
-	os.Stdout.Write(shellcomp.Marshal(completions, directive, false))
+	e.Stdout().Write(shellcomp.Marshal(completions, directive, false))
 
 	// Print some helpful info to stderr for the user to understand.
 	// Output from stderr must be ignored by the completion script.
-	fmt.Fprintf(os.Stderr, "Completion ended with directive: %s\n", directive)
+	fmt.Fprintf(e.Stderr(), "Completion ended with directive: %s\n", directive)
 
-	return nil
+	return debugErr
 }
 
-// completionError prints the specified completion message to stderr.
-func completionError(s string) {
+// completionError prints the specified completion message to stderr. It returns
+// an error if the message couldn't be written to the completion script's log.
+func completionError(e *Entrypoint, s string) error {
 	s = fmt.Sprintf("[Error] %s\n", s)
-	completionDebug(s)
+	err := completionDebug(s)
+	if err != nil {
+		fmt.Fprintln(e.Stderr(), "Error:", err)
+	}
 
 	// Note that completion printouts should never be on stdout as they would
 	// be wrongly interpreted as actual completion choices by the completion script.
-	fmt.Fprint(os.Stderr, s)
+	fmt.Fprint(e.Stderr(), s)
+	return err
 }
 
 // completionDebug prints the specified string to the same file as where the
 // completion script prints its logs.
-func completionDebug(s string) {
+func completionDebug(s string) error {
 	// Such logs are only printed when the user has set the environment
 	// variable BASH_COMP_DEBUG_FILE to the path of some file to be used.
 	if path := os.Getenv("BASH_COMP_DEBUG_FILE"); path != "" {
 		if f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644); err == nil {
 			defer f.Close()
 
-			// Write msg to f. If there's an error, exit.
 			if _, err := f.WriteString(s); err != nil {
-				fmt.Fprintln(os.Stderr, "Error:", err)
-				os.Exit(exit.NotOK)
+				return exit.Wrap(err, exit.NotOK)
 			}
 		}
 	}
+	return nil
 }
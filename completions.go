@@ -1,6 +1,7 @@
 package exoskeleton
 
 import (
+	"context"
 	"strings"
 
 	"github.com/square/exoskeleton/v2/pkg/shellcomp"
@@ -10,7 +11,7 @@ import (
 // It is used by commands like 'help' and 'which' which expect their arguments
 // to be command names.
 func CompleteCommands(e *Entrypoint, args, env []string) ([]string, shellcomp.Directive, error) {
-	return e.completionsFor(args, env, false)
+	return e.completionsFor(context.Background(), args, env, false)
 }
 
 // CompleteFiles is a CompleteFunc that provides completions for files and paths.
@@ -18,7 +19,7 @@ func CompleteFiles(e *Entrypoint, args, env []string) ([]string, shellcomp.Direc
 	return nil, shellcomp.DirectiveDefault, nil
 }
 
-func (e *Entrypoint) completionsFor(args, env []string, completeArgs bool) ([]string, shellcomp.Directive, error) {
+func (e *Entrypoint) completionsFor(ctx context.Context, args, env []string, completeArgs bool) ([]string, shellcomp.Directive, error) {
 	if len(args) == 0 {
 		return nil, shellcomp.DirectiveNoFileComp, nil
 	}
@@ -29,7 +30,7 @@ func (e *Entrypoint) completionsFor(args, env []string, completeArgs bool) ([]st
 	trimmedArgs := args[:len(args)-1]
 
 	// Find the real command for which completion must be performed
-	finalCmd, finalCmdArgs, err := e.Identify(trimmedArgs)
+	finalCmd, finalCmdArgs, err := e.IdentifyContext(ctx, trimmedArgs)
 	if err != nil {
 		return nil, shellcomp.DirectiveError, err
 	}
@@ -38,12 +39,12 @@ func (e *Entrypoint) completionsFor(args, env []string, completeArgs bool) ([]st
 		return nil, shellcomp.DirectiveNoFileComp, nil
 	}
 
-	return finalCmd.Complete(e, append(finalCmdArgs, toComplete), env)
+	return CompleteCommand(ctx, finalCmd, e, append(finalCmdArgs, toComplete), env)
 }
 
 func completionsForSubcommands(cmd Command, args []string) ([]string, shellcomp.Directive, error) {
 	cmds, err := cmd.Subcommands()
-	if err != nil {
+	if err != nil && !isPartial(err) {
 		return nil, shellcomp.DirectiveError, err
 	}
 	return cmds.completionsFor(args)
@@ -58,6 +59,10 @@ func (c Commands) completionsFor(args []string) ([]string, shellcomp.Directive,
 		seen := make(map[string]bool)
 
 		for _, subcmd := range c {
+			if IsHidden(subcmd) {
+				continue
+			}
+
 			name := subcmd.Name()
 
 			if seen[name] {
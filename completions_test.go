@@ -1,6 +1,7 @@
 package exoskeleton
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -50,7 +51,7 @@ func TestCompletionsFor(t *testing.T) {
 	}
 
 	for _, s := range scenarios {
-		actualCompletions, _, _ := entrypoint.completionsFor(s.args, nil, s.completeArgs)
+		actualCompletions, _, _ := entrypoint.completionsFor(context.Background(), s.args, nil, s.completeArgs)
 		assert.Equal(t, s.expectedCompletions, actualCompletions, fmt.Sprintf("completionsFor(\"%v\", %t)", s.args, s.completeArgs))
 	}
 }
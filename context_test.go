@@ -0,0 +1,55 @@
+package exoskeleton
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContext(t *testing.T) {
+	dir := t.TempDir()
+	writeScript(t, filepath.Join(dir, "slow"), "#!/bin/sh\nexec sleep 5\n")
+	writeScript(t, filepath.Join(dir, "mod.exoskeleton"), "#!/bin/sh\nexec sleep 5\n")
+
+	entrypoint, err := New([]string{dir}, WithName("e"))
+	assert.NoError(t, err)
+
+	start := time.Now()
+
+	t.Run("ExecContext", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		cmd, args, err := entrypoint.IdentifyContext(ctx, []string{"slow"})
+		assert.NoError(t, err)
+		assert.Error(t, ExecCommand(ctx, cmd, entrypoint, args, nil))
+	})
+
+	t.Run("CompleteContext", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		cmd := entrypoint.cmds.Find("slow")
+		_, _, err := CompleteCommand(ctx, cmd, entrypoint, []string{""}, nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("IdentifyContext", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		_, _, err := entrypoint.IdentifyContext(ctx, []string{"mod", "sub"})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+		// Discovery is attempted again with the next caller's context
+		ctx, cancel = context.WithCancel(context.Background())
+		cancel()
+		_, _, err = entrypoint.IdentifyContext(ctx, []string{"mod", "sub"})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	assert.Less(t, time.Since(start), 4*time.Second)
+}
@@ -2,12 +2,12 @@ package exoskeleton
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"io/fs"
-	"os"
 	"strings"
 
 	"github.com/block/opencli-go"
@@ -42,6 +42,12 @@ type ContractReporter interface {
 // Discovery will try the next contract in the list.
 var ErrNotApplicable = errors.New("contract does not apply")
 
+// notApplicable returns ErrNotApplicable with the reason a contract doesn't
+// apply, which is logged during discovery.
+func notApplicable(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrNotApplicable, fmt.Sprintf(format, args...))
+}
+
 // CommandError records an error that occurred with a command's implementation of its interface
 type CommandError struct {
 	Message string
@@ -64,13 +70,16 @@ type CommandHelpError struct{ CommandError }
 // respond to `--describe-commands`
 type CommandDescribeError struct{ CommandError }
 
+// CommandCompleteError indicates that an executable did not properly respond
+// to `--complete`
+type CommandCompleteError struct{ CommandError }
+
 func readSummaryFromModulefile(cmd *directoryCommand) (string, error) {
 	var summary string
 
-	f, err := os.Open(cmd.path)
-	if err == nil {
-		defer f.Close()
-		summary, err = getMessageFromMagicComments(f, "summary")
+	metadata, err := cmd.readMetadata()
+	if err == nil && metadata.Summary != nil {
+		summary = *metadata.Summary
 	}
 
 	if err != nil {
@@ -132,9 +141,8 @@ func readHelpFromExecutable(cmd *executableCommand) (string, error) {
 
 // describeCommandsRaw executes --describe-commands and returns the raw JSON output.
 // Errors are wrapped appropriately.
-func describeCommandsRaw(m *executableCommand) (string, error) {
-	cmd := m.Command("--describe-commands")
-	out, err := m.output(cmd)
+func describeCommandsRaw(ctx context.Context, m *executableCommand) (string, error) {
+	cmd, out, err := m.metadataOutput(ctx, "describe", nil, "--describe-commands")
 	if err != nil {
 		err = fmt.Errorf("exec '%s': %w", strings.Join(cmd.Args, " "), err)
 		return "", exit.Wrap(
@@ -172,9 +180,9 @@ func parseDescribeCommands(cmd *executableCommand, out string) (*commandDescript
 
 // describeCommandsDefault is the default describeFunc used by ExecutableContract.
 // It invokes --describe-commands and parses the JSON output.
-func describeCommandsDefault(cmd *executableCommand) (*commandDescriptor, error) {
-	out, err := cmd.cache.Fetch(cmd, "describe-commands", func() (string, error) {
-		return describeCommandsRaw(cmd)
+func describeCommandsDefault(ctx context.Context, cmd *executableCommand) (*commandDescriptor, error) {
+	out, err := fetch(ctx, cmd.cache, cmd, "describe-commands", func(ctx context.Context) (string, error) {
+		return describeCommandsRaw(ctx, cmd)
 	})
 	if err != nil {
 		return nil, err
@@ -197,13 +205,13 @@ type commandDescriptor struct {
 }
 
 func readSummaryFromShellScript(cmd *shellScriptCommand) (string, error) {
-	f, err := os.Open(cmd.path)
+	f, err := cmd.open()
 	if err != nil {
 		return "", err
 	}
 	defer f.Close()
 
-	summary, err := getMessageFromMagicComments(f, "summary")
+	summary, err := getMessageFromMagicComments(f, "summary", cmd.commentLeader)
 	if err != nil {
 		return "",
 			exit.Wrap(
@@ -227,13 +235,13 @@ func readSummaryFromShellScript(cmd *shellScriptCommand) (string, error) {
 }
 
 func readHelpFromShellScript(cmd *shellScriptCommand) (string, error) {
-	f, err := os.Open(cmd.path)
+	f, err := cmd.open()
 	if err != nil {
 		return "", err
 	}
 	defer f.Close()
 
-	help, err := getMessageFromMagicComments(f, "help")
+	help, err := getMessageFromMagicComments(f, "help", cmd.commentLeader)
 	if err != nil {
 		return "",
 			exit.Wrap(
@@ -256,26 +264,50 @@ func readHelpFromShellScript(cmd *shellScriptCommand) (string, error) {
 	return help, nil
 }
 
-func getMessageFromMagicComments(f *os.File, message string) (string, error) {
+// defaultCommentLeader is the string that begins magic comments in shell scripts
+// and module metadata files.
+const defaultCommentLeader = "#"
+
+// getMessageFromMagicComments reads magic comments (like '# SUMMARY:') from f.
+// The leader is the string that begins a comment in the file's language (like
+// "#" or "//"). If it is empty, defaultCommentLeader is used.
+func getMessageFromMagicComments(f io.Reader, message, leader string) (string, error) {
+	if leader == "" {
+		leader = defaultCommentLeader
+	}
+
 	reader := bufio.NewReader(f)
 	switch message {
 	case "summary":
-		return getSummaryFromMagicComments(reader)
+		return getSummaryFromMagicComments(reader, leader)
 	case "help":
-		return getHelpFromMagicComments(reader)
+		return getHelpFromMagicComments(reader, leader)
 	default:
 		panic("Unhandled message: " + message)
 	}
 }
 
-func getSummaryFromMagicComments(reader *bufio.Reader) (string, error) {
+// cutCommentLeader returns line without the comment leader it begins with and
+// true, or false if it doesn't begin with leader. Comments in Lisps are
+// conventionally begun with ";;" (or more), so any run of ";" is a leader.
+func cutCommentLeader(line, leader string) (string, bool) {
+	rest, ok := strings.CutPrefix(line, leader)
+	if ok && leader == ";" {
+		rest = strings.TrimLeft(rest, ";")
+	}
+	return rest, ok
+}
+
+func getSummaryFromMagicComments(reader *bufio.Reader, leader string) (string, error) {
 	var line string
 	var err error
 
 	for {
 		line, err = reader.ReadString('\n')
-		if strings.HasPrefix(line, "# SUMMARY:") {
-			return strings.TrimRight(strings.TrimPrefix(line[10:], " "), "\n"), nil
+		if comment, ok := cutCommentLeader(line, leader); ok {
+			if summary, ok := strings.CutPrefix(comment, " SUMMARY:"); ok {
+				return strings.TrimRight(strings.TrimPrefix(summary, " "), "\n"), nil
+			}
 		}
 		if err == io.EOF {
 			return "", nil
@@ -286,7 +318,7 @@ func getSummaryFromMagicComments(reader *bufio.Reader) (string, error) {
 	}
 }
 
-func getHelpFromMagicComments(reader *bufio.Reader) (string, error) {
+func getHelpFromMagicComments(reader *bufio.Reader, leader string) (string, error) {
 	var line string
 	var err error
 	var help string
@@ -302,14 +334,16 @@ func getHelpFromMagicComments(reader *bufio.Reader) (string, error) {
 			return "", err
 		}
 
-		if strings.HasPrefix(line, "# USAGE:") {
-			help += "USAGE\n   " + strings.TrimRight(strings.TrimPrefix(line[8:], " "), "\n") + "\n\n"
+		comment, isComment := cutCommentLeader(line, leader)
+
+		if usage, ok := strings.CutPrefix(comment, " USAGE:"); isComment && ok {
+			help += "USAGE\n   " + strings.TrimRight(strings.TrimPrefix(usage, " "), "\n") + "\n\n"
 		}
 
 		if inHelpText {
-			if strings.HasPrefix(line, "#") {
-				if len(line) > 2 {
-					help += line[2:]
+			if isComment {
+				if len(comment) > 1 {
+					help += comment[1:]
 				} else {
 					help += "\n"
 				}
@@ -318,30 +352,124 @@ func getHelpFromMagicComments(reader *bufio.Reader) (string, error) {
 			}
 		}
 
-		if strings.HasPrefix(line, "# HELP:") {
-			help += strings.TrimPrefix(line[7:], " ")
+		if text, ok := strings.CutPrefix(comment, " HELP:"); isComment && ok {
+			help += strings.TrimPrefix(text, " ")
 			inHelpText = true
 		}
 	}
 }
 
+// scriptMetadata holds the magic comments that describe how a script is listed
+// and identified (as opposed to its summary and help).
+type scriptMetadata struct {
+	aliases    []string
+	hidden     bool
+	heading    string
+	deprecated *string
+	isDefault  bool
+	complete   *string
+}
+
+// getMetadataFromMagicComments reads magic comments like these from a script:
+//
+//	# ALIASES: a, b
+//	# HIDDEN
+//	# HEADING: Deploy
+//	# DEPRECATED: use foo instead
+//	# DEFAULT
+//	# COMPLETE: start stop status
+//
+// Only the comments at the top of the script (after its shebang) are read: it
+// stops at the first line that is neither blank nor a comment. The body of a
+// HELP comment is help text, not metadata, so it is skipped.
+func getMetadataFromMagicComments(f io.Reader, leader string) (scriptMetadata, error) {
+	var metadata scriptMetadata
+	var inHelpText bool
+
+	if leader == "" {
+		leader = defaultCommentLeader
+	}
+
+	reader := bufio.NewReader(f)
+	for n := 1; ; n++ {
+		line, err := reader.ReadString('\n')
+		line = strings.TrimRight(line, " \r\n")
+
+		if n == 1 && strings.HasPrefix(line, "#!") {
+			// Skip the shebang
+		} else if line == "" {
+			inHelpText = false
+		} else if comment, ok := cutCommentLeader(line, leader); !ok {
+			return metadata, nil
+		} else if inHelpText {
+			// Skip the body of the HELP comment
+		} else if directive, ok := strings.CutPrefix(comment, " "); ok {
+			keyword, value, _ := strings.Cut(directive, ":")
+			value = strings.TrimSpace(value)
+
+			switch keyword {
+			case "ALIASES":
+				for _, alias := range strings.Split(value, ",") {
+					if alias = strings.TrimSpace(alias); alias != "" {
+						metadata.aliases = append(metadata.aliases, alias)
+					}
+				}
+			case "HIDDEN":
+				metadata.hidden = true
+			case "HEADING":
+				metadata.heading = value
+			case "DEPRECATED":
+				metadata.deprecated = &value
+			case "DEFAULT":
+				metadata.isDefault = true
+			case "COMPLETE":
+				metadata.complete = &value
+			case "HELP":
+				inHelpText = true
+			}
+		}
+
+		if err == io.EOF {
+			return metadata, nil
+		}
+		if err != nil {
+			return metadata, err
+		}
+	}
+}
+
 func getMessageFromExecution(c *executableCommand, message string) (string, error) {
-	cmd := c.Command("--" + message)
-	out, err := c.output(cmd)
+	cmd, out, err := c.metadataOutput(context.Background(), message, nil, "--"+message)
 	if err != nil {
 		err = fmt.Errorf("exec '%s': %w", strings.Join(cmd.Args, " "), err)
 	}
 	return strings.TrimRight(string(out), "\n"), err
 }
 
-func getCompletionsFromExecutable(c *executableCommand, args, env []string) ([]string, shellcomp.Directive, error) {
-	cmd := c.Command(append([]string{"--complete", "--"}, args...)...)
-	cmd.Env = env
+func getCompletionsFromExecutable(ctx context.Context, c *executableCommand, args, env []string) ([]string, shellcomp.Directive, error) {
+	cmd, out, err := c.metadataOutput(ctx, "complete", env, append([]string{"--complete", "--"}, args...)...)
+	if err != nil {
+		err = fmt.Errorf("exec '%s': %w", strings.Join(cmd.Args, " "), err)
+		return []string{}, shellcomp.DirectiveNoFileComp, completeError(c, err)
+	}
 
-	out, err := c.output(cmd)
+	completions, directive, err := shellcomp.Unmarshal(out)
 	if err != nil {
-		return []string{}, shellcomp.DirectiveNoFileComp, err
+		err = fmt.Errorf("error parsing output from `%s --complete`: %w", c.path, err)
+		return completions, directive, completeError(c, err)
 	}
+	return completions, directive, nil
+}
 
-	return shellcomp.Unmarshal(out)
+func completeError(cmd *executableCommand, err error) error {
+	return exit.Wrap(
+		CommandCompleteError{
+			CommandError{
+				Message: fmt.Sprintf("complete('%s'): %s", Usage(cmd), err),
+				Command: cmd,
+				Cause:   err,
+			},
+		},
+		exit.InternalError,
+	)
 }
@@ -1,8 +1,8 @@
 package exoskeleton
 
 import (
+	"errors"
 	"io/fs"
-	"os"
 	"path/filepath"
 )
 
@@ -17,14 +17,14 @@ type DirectoryContract struct {
 func (c *DirectoryContract) BuildCommand(path string, info fs.DirEntry, parent Command, d DiscoveryContext) (Command, error) {
 	// Only applies to directories
 	if !info.IsDir() {
-		return nil, ErrNotApplicable
+		return nil, notApplicable("not a directory")
 	}
 
-	modulefilePath := filepath.Join(path, c.MetadataFilename)
+	modulefilePath := joinPath(d.FS(), path, c.MetadataFilename)
 
 	// If the directory doesn't contain the modulefile, it's just a regular directory
-	if !exists(modulefilePath) {
-		return nil, ErrNotApplicable
+	if !exists(d.FS(), modulefilePath) {
+		return nil, notApplicable("no %s file", c.MetadataFilename)
 	}
 
 	// Stop discovering modules if we've searched past maxDepth
@@ -37,16 +37,17 @@ func (c *DirectoryContract) BuildCommand(path string, info fs.DirEntry, parent C
 			parent:       parent,
 			path:         modulefilePath,
 			name:         filepath.Base(path),
-			discoveredIn: filepath.Dir(path),
+			discoveredIn: dirPath(d.FS(), path),
 			executor:     d.Executor(),
 			cache:        d.Cache(),
+			fsys:         d.FS(),
 			contract:     "Directory",
 		},
 		discoverer: d.Next(),
 	}, nil
 }
 
-func exists(path string) bool {
-	_, err := os.Stat(path)
-	return err == nil || !os.IsNotExist(err)
+func exists(fsys fs.FS, path string) bool {
+	_, err := fs.Stat(fsys, path)
+	return err == nil || !errors.Is(err, fs.ErrNotExist)
 }
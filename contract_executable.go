@@ -17,20 +17,20 @@ type ExecutableContract struct {
 func (c *ExecutableContract) BuildCommand(path string, info fs.DirEntry, parent Command, d DiscoveryContext) (Command, error) {
 	// Only applies to files
 	if info.IsDir() {
-		return nil, ErrNotApplicable
+		return nil, notApplicable("is a directory")
 	}
 
 	// Must have the configured extension
 	name := filepath.Base(path)
-	if filepath.Ext(name) != executableModuleExtension {
-		return nil, ErrNotApplicable
+	if filepath.Ext(name) != executableModuleExtension || name == executableModuleExtension {
+		return nil, notApplicable("no %s extension", executableModuleExtension)
 	}
 
 	// Must be executable
 	if ok, err := isExecutable(info); err != nil {
 		return nil, err
 	} else if !ok {
-		return nil, ErrNotApplicable
+		return nil, notApplicable("not executable")
 	}
 
 	commandName := strings.TrimSuffix(name, executableModuleExtension)
@@ -42,9 +42,10 @@ func (c *ExecutableContract) BuildCommand(path string, info fs.DirEntry, parent
 			parent:       parent,
 			path:         path,
 			name:         commandName,
-			discoveredIn: filepath.Dir(path),
+			discoveredIn: dirPath(d.FS(), path),
 			executor:     d.Executor(),
 			cache:        d.Cache(),
+			fsys:         d.FS(),
 			contract:     "Executable",
 		}, nil
 	}
@@ -53,9 +54,10 @@ func (c *ExecutableContract) BuildCommand(path string, info fs.DirEntry, parent
 		parent:       parent,
 		path:         path,
 		name:         commandName,
-		discoveredIn: filepath.Dir(path),
+		discoveredIn: dirPath(d.FS(), path),
 		executor:     d.Executor(),
 		cache:        d.Cache(),
+		fsys:         d.FS(),
 		discoverer:   d.Next(),
 		contract:     "Executable",
 	}, nil
@@ -0,0 +1,24 @@
+package exoskeleton
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutableContractIgnoresBareExtension(t *testing.T) {
+	// A module's metadata file may be executable, but it isn't an executable
+	// module (which would have no name)
+	path := filepath.Join(t.TempDir(), executableModuleExtension)
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"), 0755))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+
+	cmd, err := (&ExecutableContract{}).BuildCommand(path, fs.FileInfoToDirEntry(info), nil, nil)
+	assert.Nil(t, cmd)
+	assert.ErrorIs(t, err, ErrNotApplicable)
+}
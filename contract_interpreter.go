@@ -0,0 +1,67 @@
+package exoskeleton
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// InterpreterContract handles scripts that are run by an interpreter chosen by
+// their file extension.
+//
+// Unlike ShellScriptContract, it doesn't require scripts to be executable or to
+// start with a shebang (#!), so it also works for checkouts that have lost their
+// mode bits. The extension is removed from the command's name: with the
+// interpreter {".py": "python3"}, 'deploy.py' becomes the command 'deploy' and
+// runs as 'python3 deploy.py'.
+//
+// Scripts provide metadata via magic comments (like SUMMARY: and HELP:) written
+// with the comment syntax of their language, e.g. "// SUMMARY:" in JavaScript,
+// which is inferred from their interpreter or else from their extension.
+type InterpreterContract struct {
+	// Interpreters maps file extensions (including the leading dot) to the command
+	// that runs files with that extension. The command may include arguments:
+	//
+	//	map[string]string{
+	//		".py": "python3",
+	//		".rb": "ruby",
+	//		".js": "node",
+	//		".ts": "deno run --allow-all",
+	//		".sh": "bash",
+	//	}
+	Interpreters map[string]string
+
+	// CommentLeaders adds to or overrides the languages that are recognized
+	// (see ShellScriptContract.CommentLeaders).
+	CommentLeaders map[string]string
+}
+
+func (c *InterpreterContract) BuildCommand(path string, info fs.DirEntry, parent Command, d DiscoveryContext) (Command, error) {
+	// Only applies to files
+	if info.IsDir() {
+		return nil, notApplicable("is a directory")
+	}
+
+	// Must have an extension that is mapped to an interpreter
+	name := filepath.Base(path)
+	ext := filepath.Ext(name)
+	interpreter := strings.Fields(c.Interpreters[ext])
+	if ext == "" || ext == name || len(interpreter) == 0 {
+		return nil, notApplicable("no interpreter for its extension")
+	}
+
+	return &shellScriptCommand{
+		executableCommand: executableCommand{
+			parent:       parent,
+			path:         path,
+			name:         strings.TrimSuffix(name, ext),
+			discoveredIn: dirPath(d.FS(), path),
+			executor:     d.Executor(),
+			cache:        d.Cache(),
+			fsys:         d.FS(),
+			interpreter:  interpreter,
+			contract:     "Interpreter",
+		},
+		commentLeader: commentLeaderFor("#!"+strings.Join(interpreter, " "), path, c.CommentLeaders),
+	}, nil
+}
@@ -0,0 +1,90 @@
+package exoskeleton
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterpreterContractBuildsCommandsWithoutModeBits(t *testing.T) {
+	contract := &InterpreterContract{Interpreters: map[string]string{".sh": "sh", ".js": "node --no-warnings"}}
+	d := &discoverer{maxDepth: -1, executor: defaultExecutor, cache: nullCache{}, contracts: []Contract{contract}}
+
+	cmds, errs := d.DiscoverIn(filepath.Join(fixtures, "interpreted"), nil)
+	assert.Empty(t, errs)
+	assert.Equal(t, "greet\nhello", namesOf(cmds))
+
+	greet := cmds.Find("greet").(*shellScriptCommand)
+	assert.Equal(t, filepath.Join(fixtures, "interpreted", "greet.sh"), greet.Path())
+	assert.Equal(t, "Interpreter", greet.Contract())
+	assert.Equal(t, []string{"sh", greet.Path(), "world"}, greet.Command("world").Args)
+
+	out, err := greet.Command("world").Output()
+	assert.NoError(t, err)
+	assert.Equal(t, "hello world\n", string(out))
+
+	hello := cmds.Find("hello").(*shellScriptCommand)
+	assert.Equal(t, []string{"node", "--no-warnings", hello.Path()}, hello.Command().Args)
+}
+
+func TestInterpreterContractReadsMagicCommentsInTheLanguagesSyntax(t *testing.T) {
+	contract := &InterpreterContract{Interpreters: map[string]string{".sh": "sh", ".js": "node"}}
+	d := &discoverer{maxDepth: -1, executor: defaultExecutor, cache: nullCache{}}
+
+	scenarios := []struct {
+		file    string
+		summary string
+		help    string
+	}{
+		{"greet.sh", "Greets someone", "USAGE\n   greet <name>"},
+		{"hello.js", "Says hello", "USAGE\n   hello"},
+	}
+
+	for _, s := range scenarios {
+		path := filepath.Join(fixtures, "interpreted", s.file)
+		info, err := os.Lstat(path)
+		assert.NoError(t, err)
+
+		cmd, err := contract.BuildCommand(path, fs.FileInfoToDirEntry(info), nil, d)
+		assert.NoErrorf(t, err, "Given file=%s", s.file)
+
+		summary, err := cmd.Summary()
+		assert.NoErrorf(t, err, "Given file=%s", s.file)
+		assert.Equalf(t, s.summary, summary, "Given file=%s", s.file)
+
+		help, err := cmd.Help()
+		assert.NoErrorf(t, err, "Given file=%s", s.file)
+		assert.Equalf(t, s.help, help, "Given file=%s", s.file)
+	}
+}
+
+func TestInterpreterContractUsesCommentLeaderOverrides(t *testing.T) {
+	contract := &InterpreterContract{
+		Interpreters:   map[string]string{".foo": "sh", ".bar": "custom --flag"},
+		CommentLeaders: map[string]string{".foo": "%", "custom": "!"},
+	}
+	d := &discoverer{maxDepth: -1, executor: defaultExecutor, cache: nullCache{}}
+
+	dir := t.TempDir()
+	scenarios := map[string]string{
+		"by-extension.foo":   "% SUMMARY: Overridden by extension\n",
+		"by-interpreter.bar": "! SUMMARY: Overridden by interpreter\n",
+	}
+
+	for file, content := range scenarios {
+		path := filepath.Join(dir, file)
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		info, err := os.Lstat(path)
+		assert.NoError(t, err)
+
+		cmd, err := contract.BuildCommand(path, fs.FileInfoToDirEntry(info), nil, d)
+		assert.NoErrorf(t, err, "Given file=%s", file)
+
+		summary, err := cmd.Summary()
+		assert.NoErrorf(t, err, "Given file=%s", file)
+		assert.Containsf(t, summary, "Overridden by", "Given file=%s", file)
+	}
+}
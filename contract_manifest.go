@@ -0,0 +1,201 @@
+package exoskeleton
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+)
+
+const defaultManifestFilename = "commands.json"
+
+// ManifestContract handles JSON manifests that declare commands up front, so that
+// menus can be rendered without executing anything.
+//
+// A manifest lists commands by name along with the executable that implements
+// each one. Paths are relative to the directory containing the manifest:
+//
+//	{
+//	  "commands": [
+//	    {"name": "deploy", "path": "bin/deploy", "summary": "Deploy a service", "aliases": ["d"]},
+//	    {
+//	      "name": "db",
+//	      "summary": "Database tools",
+//	      "defaultCommand": "migrate",
+//	      "commands": [
+//	        {"name": "migrate", "path": "bin/db-migrate", "summary": "Run migrations"}
+//	      ]
+//	    }
+//	  ]
+//	}
+//
+// The commands in a manifest are listed in place of the manifest itself.
+// Commands without a path are modules, which must declare subcommands; their
+// subcommands that also omit a path are invoked through the nearest ancestor's
+// executable with their names as arguments (like commands described by
+// --describe-commands). Commands that omit their summary or help fall back to
+// executing with --summary or --help.
+//
+// ManifestContract is not one of the default contracts. Keep the executables
+// a manifest refers to out of the search paths (e.g. in a "bin" directory
+// without module metadata) so they aren't also discovered by other contracts.
+type ManifestContract struct {
+	// Filename is the name of the manifest file. (Default: "commands.json")
+	Filename string
+}
+
+// MultiContract is implemented by Contracts that can build several Commands
+// from a single file (for example, a manifest). During discovery, BuildCommands
+// is used in place of BuildCommand for Contracts that implement it.
+type MultiContract interface {
+	Contract
+
+	// BuildCommands constructs Commands using this contract's rules.
+	// It follows the same conventions as BuildCommand: it returns ErrNotApplicable
+	// if this contract doesn't handle the file/directory and nil, nil if the
+	// file/directory should be ignored.
+	BuildCommands(path string, info fs.DirEntry, parent Command, d DiscoveryContext) (Commands, error)
+}
+
+var _ MultiContract = &ManifestContract{}
+
+type manifestDescriptor struct {
+	Commands []*manifestEntry `json:"commands"`
+}
+
+type manifestEntry struct {
+	Name           string           `json:"name"`
+	Path           string           `json:"path,omitempty"`
+	Aliases        []string         `json:"aliases,omitempty"`
+	Summary        *string          `json:"summary,omitempty"`
+	Help           *string          `json:"help,omitempty"`
+	Commands       []*manifestEntry `json:"commands,omitempty"`
+	DefaultCommand string           `json:"defaultCommand,omitempty"`
+}
+
+// BuildCommand is not used during discovery: manifests declare any number of
+// commands, so ManifestContract builds them with BuildCommands.
+func (c *ManifestContract) BuildCommand(path string, info fs.DirEntry, parent Command, d DiscoveryContext) (Command, error) {
+	return nil, notApplicable("manifests are built with BuildCommands")
+}
+
+func (c *ManifestContract) BuildCommands(path string, info fs.DirEntry, parent Command, d DiscoveryContext) (Commands, error) {
+	// Only applies to files
+	if info.IsDir() {
+		return nil, notApplicable("is a directory")
+	}
+
+	if filepath.Base(path) != c.filename() {
+		return nil, notApplicable("not named %s", c.filename())
+	}
+
+	b, err := fs.ReadFile(d.FS(), path)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest manifestDescriptor
+	if err := json.Unmarshal(b, &manifest); err != nil {
+		return nil, fmt.Errorf("error parsing manifest: %w", err)
+	}
+
+	return buildManifestCommands(parent, nil, path, manifest.Commands, d)
+}
+
+func (c *ManifestContract) filename() string {
+	if c.Filename == "" {
+		return defaultManifestFilename
+	}
+	return c.Filename
+}
+
+// buildManifestCommands constructs Commands from manifest entries. The given
+// owner is the nearest ancestor with an executable (or nil): entries without
+// a path of their own are invoked through it.
+//
+// It returns an error if an entry has no path, no subcommands, and no ancestor
+// with an executable, since there would be nothing to execute.
+func buildManifestCommands(parent Command, owner *manifestCommand, manifestPath string, entries []*manifestEntry, d DiscoveryContext) (Commands, error) {
+	cmds := Commands{}
+	for _, entry := range entries {
+		if entry.Path == "" && owner == nil && len(entry.Commands) == 0 {
+			return nil, fmt.Errorf("error parsing manifest: %q has no path and no commands", entry.Name)
+		}
+
+		c := &manifestCommand{
+			executableCommand: executableCommand{
+				parent:            parent,
+				path:              manifestPath,
+				name:              entry.Name,
+				aliases:           entry.Aliases,
+				summary:           entry.Summary,
+				discoveredIn:      dirPath(d.FS(), manifestPath),
+				defaultSubcommand: entry.DefaultCommand,
+				executor:          d.Executor(),
+				cache:             d.Cache(),
+				fsys:              d.FS(),
+				contract:          "Manifest",
+			},
+			help: entry.Help,
+		}
+
+		if entry.Path != "" {
+			c.path = entry.Path
+			if !filepath.IsAbs(c.path) {
+				c.path = joinPath(d.FS(), dirPath(d.FS(), manifestPath), entry.Path)
+			}
+			c.hasExecutable = true
+		} else if owner != nil {
+			c.path = owner.path
+			c.args = append(append([]string{}, owner.args...), entry.Name)
+			c.hasExecutable = true
+		}
+
+		if !c.hasExecutable {
+			// Stop discovering modules if we've searched past maxDepth
+			if d.MaxDepth() == 0 {
+				continue
+			}
+
+			// Commands that can't be executed can't be asked for their summary
+			if c.summary == nil {
+				c.summary = new(string)
+			}
+		}
+
+		if len(entry.Commands) > 0 && d.MaxDepth() != 0 {
+			next := owner
+			if c.hasExecutable {
+				next = c
+			}
+			c.discoverer = d.Next()
+			subcmds, err := buildManifestCommands(c, next, manifestPath, entry.Commands, d.Next())
+			if err != nil {
+				return nil, err
+			}
+			c.cmds = subcmds
+		}
+
+		cmds = append(cmds, c)
+	}
+	return cmds, nil
+}
+
+// manifestCommand implements the Command interface for a command declared in a
+// manifest. It extends executableCommand but answers Help() from the manifest
+// when the manifest provides it.
+type manifestCommand struct {
+	executableCommand
+	help          *string
+	hasExecutable bool
+}
+
+func (cmd *manifestCommand) Help() (string, error) {
+	if cmd.help != nil {
+		return *cmd.help, nil
+	}
+	if !cmd.hasExecutable {
+		return "", nil
+	}
+	return readHelpFromExecutable(&cmd.executableCommand)
+}
@@ -0,0 +1,128 @@
+package exoskeleton
+
+import (
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManifestContractRejectsOtherFiles(t *testing.T) {
+	contract := &ManifestContract{}
+	path := filepath.Join(fixtures, "hello")
+	info, err := os.Lstat(path)
+	assert.NoError(t, err)
+
+	_, err = contract.BuildCommands(path, fs.FileInfoToDirEntry(info), nil, &discoverer{maxDepth: -1})
+	assert.ErrorIs(t, err, ErrNotApplicable)
+}
+
+func TestManifestContractBuildsCommandTree(t *testing.T) {
+	d := &discoverer{maxDepth: -1, executor: defaultExecutor, cache: nullCache{}, contracts: []Contract{&ManifestContract{}}}
+	cmds, errs := d.DiscoverIn(filepath.Join(fixtures, "manifest"), nil)
+	assert.Empty(t, errs)
+
+	all, errs := cmds.Flatten()
+	assert.Empty(t, errs)
+	assert.Equal(t, "deploy\nhello\ndb\nmigrate\necho\nsub", namesOf(all))
+
+	deploy := cmds.Find("d")
+	assert.Equal(t, "deploy", deploy.Name())
+	assert.Equal(t, filepath.Join(fixtures, "manifest", "bin", "deploy"), deploy.Path())
+	assert.Equal(t, "Manifest", deploy.(ContractReporter).Contract())
+
+	summary, err := deploy.Summary()
+	assert.NoError(t, err)
+	assert.Equal(t, "Deploys a service", summary)
+
+	help, err := deploy.Help()
+	assert.NoError(t, err)
+	assert.Equal(t, "USAGE\n   deploy <service>", help)
+
+	// Falls back to --summary when the manifest doesn't declare a summary
+	summary, err = cmds.Find("hello").Summary()
+	assert.NoError(t, err)
+	assert.Equal(t, `Prints "hello"`, summary)
+
+	// Modules without an executable are listed with their subcommands
+	db := cmds.Find("db")
+	assert.Equal(t, filepath.Join(fixtures, "manifest", "commands.json"), db.Path())
+	assert.Equal(t, "migrate", db.DefaultSubcommand().Name())
+
+	// Subcommands without a path are invoked through their parent's executable
+	sub := all.Find("sub").(*manifestCommand)
+	assert.Equal(t, filepath.Join(fixtures, "echoargs"), sub.Path())
+	assert.Equal(t, []string{"sub"}, sub.args)
+}
+
+func TestManifestContractMaxDepthZeroOmitsModules(t *testing.T) {
+	d := &discoverer{maxDepth: 0, executor: defaultExecutor, cache: nullCache{}, contracts: []Contract{&ManifestContract{}}}
+	cmds, errs := d.DiscoverIn(filepath.Join(fixtures, "manifest"), nil)
+	assert.Empty(t, errs)
+
+	all, errs := cmds.Flatten()
+	assert.Empty(t, errs)
+	assert.Equal(t, "deploy\nhello\necho", namesOf(all))
+}
+
+func TestManifestContractRendersMenuWithoutExecuting(t *testing.T) {
+	var executions int32
+	executor := func(cmd *exec.Cmd) error {
+		atomic.AddInt32(&executions, 1)
+		return cmd.Run()
+	}
+
+	entrypoint := &Entrypoint{name: "e", maxDepth: -1, executor: executor, cache: nullCache{}, contracts: []Contract{&ManifestContract{}}}
+	entrypoint.cmds = entrypoint.discoverIn([]string{filepath.Join(fixtures, "manifest")})
+	entrypoint.cmds = Commands{entrypoint.cmds.Find("deploy"), entrypoint.cmds.Find("db")}
+
+	menu, errs := MenuFor(entrypoint, &MenuOptions{Depth: -1, Theme: &PlainTheme})
+	assert.Empty(t, errs)
+	assert.Equal(t, `COMMANDS
+   db migrate  Runs migrations
+   deploy      Deploys a service`, sections(menu))
+	assert.Equal(t, int32(0), atomic.LoadInt32(&executions))
+}
+
+func TestManifestContractRejectsEntriesWithNothingToExecute(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "commands.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"commands": [{"name": "empty", "summary": "Does nothing"}]}`), 0644))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+
+	d := &discoverer{maxDepth: -1, executor: defaultExecutor, cache: nullCache{}}
+	cmds, err := (&ManifestContract{}).BuildCommands(path, fs.FileInfoToDirEntry(info), nil, d)
+	assert.Nil(t, cmds)
+	assert.EqualError(t, err, `error parsing manifest: "empty" has no path and no commands`)
+}
+
+func TestManifestCommandsInvokedThroughTheSameExecutableAreCachedSeparately(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "bin"), 0755))
+	writeScript(t, filepath.Join(dir, "bin", "deploy"), "#!/bin/sh\necho \"summary of $1\"\n")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "commands.json"), []byte(`{
+  "commands": [
+    {"name": "deploy", "path": "bin/deploy", "summary": "Deploys a service", "commands": [{"name": "web"}, {"name": "api"}]}
+  ]
+}`), 0644))
+
+	cache := &FileCache{Path: filepath.Join(t.TempDir(), "cache.json")}
+	d := &discoverer{maxDepth: -1, executor: defaultExecutor, cache: cache, contracts: []Contract{&ManifestContract{}}}
+	cmds, errs := d.DiscoverIn(dir, nil)
+	require.Empty(t, errs)
+
+	subcmds, err := cmds.Find("deploy").Subcommands()
+	require.NoError(t, err)
+
+	for _, name := range []string{"web", "api"} {
+		summary, err := subcmds.Find(name).Summary()
+		assert.NoError(t, err)
+		assert.Equal(t, "summary of "+name, summary)
+	}
+}
@@ -1,6 +1,7 @@
 package exoskeleton
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/fs"
@@ -39,13 +40,13 @@ type OpenCLIDescriber interface {
 
 func (c *OpenCLIContract) BuildCommand(path string, info fs.DirEntry, parent Command, d DiscoveryContext) (Command, error) {
 	if info.IsDir() {
-		return nil, ErrNotApplicable
+		return nil, notApplicable("is a directory")
 	}
 
 	if ok, err := isExecutable(info); err != nil {
 		return nil, err
 	} else if !ok {
-		return nil, ErrNotApplicable
+		return nil, notApplicable("not executable")
 	}
 
 	name := filepath.Base(path)
@@ -55,9 +56,10 @@ func (c *OpenCLIContract) BuildCommand(path string, info fs.DirEntry, parent Com
 			parent:       parent,
 			path:         path,
 			name:         name,
-			discoveredIn: filepath.Dir(path),
+			discoveredIn: dirPath(d.FS(), path),
 			executor:     d.Executor(),
 			cache:        d.Cache(),
+			fsys:         d.FS(),
 			contract:     "OpenCLI",
 		}, nil
 	}
@@ -66,9 +68,10 @@ func (c *OpenCLIContract) BuildCommand(path string, info fs.DirEntry, parent Com
 		parent:       parent,
 		path:         path,
 		name:         name,
-		discoveredIn: filepath.Dir(path),
+		discoveredIn: dirPath(d.FS(), path),
 		executor:     d.Executor(),
 		cache:        d.Cache(),
+		fsys:         d.FS(),
 		discoverer:   d.Next(),
 		describe:     describeOpenCLI,
 		contract:     "OpenCLI",
@@ -77,9 +80,9 @@ func (c *OpenCLIContract) BuildCommand(path string, info fs.DirEntry, parent Com
 
 // describeOpenCLI is a describeFunc that invokes --help-opencli
 // and parses the OpenCLI JSON output into a commandDescriptor.
-func describeOpenCLI(cmd *executableCommand) (*commandDescriptor, error) {
-	out, err := cmd.cache.Fetch(cmd, "help-opencli", func() (string, error) {
-		return helpOpenCLIRaw(cmd)
+func describeOpenCLI(ctx context.Context, cmd *executableCommand) (*commandDescriptor, error) {
+	out, err := fetch(ctx, cmd.cache, cmd, "help-opencli", func(ctx context.Context) (string, error) {
+		return helpOpenCLIRaw(ctx, cmd)
 	})
 	if err != nil {
 		return nil, err
@@ -88,9 +91,8 @@ func describeOpenCLI(cmd *executableCommand) (*commandDescriptor, error) {
 }
 
 // helpOpenCLIRaw executes --help-opencli and returns the raw JSON output.
-func helpOpenCLIRaw(m *executableCommand) (string, error) {
-	cmd := m.Command("--help-opencli")
-	out, err := m.output(cmd)
+func helpOpenCLIRaw(ctx context.Context, m *executableCommand) (string, error) {
+	cmd, out, err := m.metadataOutput(ctx, "describe", nil, "--help-opencli")
 	if err != nil {
 		err = fmt.Errorf("exec '%s': %w", strings.Join(cmd.Args, " "), err)
 		return "", exit.Wrap(
@@ -0,0 +1,87 @@
+package exoskeleton
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/square/exit"
+	"github.com/square/exoskeleton/v2/internal/probe"
+	"github.com/square/exoskeleton/v2/pkg/shellcomp"
+)
+
+// contractProbe executes an executable the way an Entrypoint does to obtain its
+// metadata, so that authors of subcommands can test that their executables
+// fulfill Exoskeleton's contracts. (See pkg/exoskeletontest.)
+type contractProbe struct {
+	cmd *executableCommand
+}
+
+// NewContractProbe returns a Probe that executes the executable at path the way
+// an Entrypoint does to obtain its metadata. It exists for pkg/exoskeletontest,
+// whose assertions are the supported way to use it.
+func NewContractProbe(path string) probe.Probe {
+	return &contractProbe{
+		cmd: &executableCommand{
+			path:         path,
+			name:         filepath.Base(path),
+			discoveredIn: filepath.Dir(path),
+			executor:     defaultExecutor,
+			cache:        nullCache{},
+		},
+	}
+}
+
+func (p *contractProbe) Summary() (string, error) {
+	return readSummaryFromExecutable(p.cmd)
+}
+
+func (p *contractProbe) Help() (string, error) {
+	return readHelpFromExecutable(p.cmd)
+}
+
+func (p *contractProbe) Complete(args ...string) ([]string, shellcomp.Directive, error) {
+	return getCompletionsFromExecutable(context.Background(), p.cmd, args, nil)
+}
+
+func (p *contractProbe) DescribeCommands() error {
+	out, err := describeCommandsRaw(context.Background(), p.cmd)
+	if err != nil {
+		return err
+	}
+	descriptor, err := parseDescribeCommands(p.cmd, out)
+	if err != nil {
+		return err
+	}
+	return p.validate(descriptor, "--describe-commands")
+}
+
+func (p *contractProbe) HelpOpenCLI() error {
+	out, err := helpOpenCLIRaw(context.Background(), p.cmd)
+	if err != nil {
+		return err
+	}
+	descriptor, err := parseOpenCLI(p.cmd, out)
+	if err != nil {
+		return err
+	}
+	return p.validate(descriptor, "--help-opencli")
+}
+
+// validate returns a CommandDescribeError if no commands were described (e.g.
+// the output was `null`). It is no stricter than discovery, which accepts
+// subcommands without names.
+func (p *contractProbe) validate(descriptor *commandDescriptor, flag string) error {
+	if descriptor != nil {
+		return nil
+	}
+	return exit.Wrap(
+		CommandDescribeError{
+			CommandError{
+				Message: fmt.Sprintf("invalid output from `%s %s`: no commands described", p.cmd.path, flag),
+				Command: p.cmd,
+			},
+		},
+		exit.InternalError,
+	)
+}
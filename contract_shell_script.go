@@ -1,55 +1,96 @@
 package exoskeleton
 
 import (
+	"bufio"
+	"context"
 	"io/fs"
-	"os"
 	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/square/exoskeleton/v2/pkg/shellcomp"
 )
 
 // ShellScriptContract handles shell scripts with magic comments.
 //
 // Scripts are detected by checking for a shebang (#!) at the start of the file.
 // They provide metadata via magic comments like "# SUMMARY:" and "# HELP:".
-type ShellScriptContract struct{}
+//
+// Scripts can also declare how they are listed and identified:
+//
+//	# ALIASES: a, b                Alternative names for the command
+//	# HIDDEN                       Omit the command from menus and completions
+//	# HEADING: Deploy              List the command under this heading in menus
+//	# DEPRECATED: use foo instead  Print a warning when the command is identified
+//	# DEFAULT                      Make the command the default subcommand of its module
+//
+// Scripts can declare how their arguments are completed, too. Scripts without a
+// "# COMPLETE:" comment are executed with --complete:
+//
+//	# COMPLETE: start stop status  Complete one of these words
+//	# COMPLETE: files              Complete file names
+//	# COMPLETE: dirs               Complete directory names
+//	# COMPLETE: ext:yaml,yml       Complete file names with these extensions
+//	# COMPLETE: none               Don't complete anything
+//
+// Magic comments are written with the comment syntax of the script's language,
+// which is inferred from the interpreter in its shebang or else from its extension:
+// a script that starts with '#!/usr/bin/env node' would use "// SUMMARY:", and
+// Lua and Clojure scripts would use "-- SUMMARY:" and ";; SUMMARY:" (any number
+// of semicolons begins a comment in Lisps).
+type ShellScriptContract struct {
+	// CommentLeaders adds to or overrides the languages that are recognized.
+	// It maps interpreter names (like "node") or extensions including the
+	// leading dot (like ".js") to the string that begins a comment (like "//").
+	CommentLeaders map[string]string
+}
 
 func (c *ShellScriptContract) BuildCommand(path string, info fs.DirEntry, parent Command, d DiscoveryContext) (Command, error) {
 	// Only applies to files
 	if info.IsDir() {
-		return nil, ErrNotApplicable
+		return nil, notApplicable("is a directory")
 	}
 
 	// Must be executable
 	if ok, err := isExecutable(info); err != nil {
 		return nil, err
 	} else if !ok {
-		return nil, ErrNotApplicable
+		return nil, notApplicable("not executable")
 	}
 
 	// Must start with shebang
-	f, err := os.Open(path)
+	f, err := d.FS().Open(path)
 	if err != nil {
 		return nil, err
 	}
 	defer f.Close()
 
+	reader := bufio.NewReader(f)
 	buffer := make([]byte, 2)
-	if _, err := f.Read(buffer); err != nil {
+	if _, err := reader.Read(buffer); err != nil {
 		return nil, err
 	}
 	if string(buffer) != "#!" {
-		return nil, ErrNotApplicable // Not a shell script
+		return nil, notApplicable("no shebang")
 	}
 
+	// Read the rest of the shebang (up to the size of the reader's buffer)
+	// to learn which language the script's magic comments are written in.
+	rest, _ := reader.ReadSlice('\n')
+	shebang := string(buffer) + string(rest)
+
 	return &shellScriptCommand{
 		executableCommand: executableCommand{
 			parent:       parent,
 			path:         path,
 			name:         filepath.Base(path),
-			discoveredIn: filepath.Dir(path),
+			discoveredIn: dirPath(d.FS(), path),
 			executor:     d.Executor(),
 			cache:        d.Cache(),
+			fsys:         d.FS(),
 			contract:     "ShellScript",
 		},
+		commentLeader: commentLeaderFor(shebang, path, c.CommentLeaders),
 	}, nil
 }
 
@@ -58,6 +99,9 @@ func (c *ShellScriptContract) BuildCommand(path string, info fs.DirEntry, parent
 // instead of executing with flags.
 type shellScriptCommand struct {
 	executableCommand
+	commentLeader string
+	metadataOnce  sync.Once
+	metadata      scriptMetadata
 }
 
 func (cmd *shellScriptCommand) Summary() (string, error) {
@@ -65,7 +109,7 @@ func (cmd *shellScriptCommand) Summary() (string, error) {
 		return *cmd.summary, nil
 	}
 
-	return cmd.cache.Fetch(cmd, "summary", func() (string, error) {
+	return fetch(context.Background(), cmd.cache, cmd, "summary", func(context.Context) (string, error) {
 		return readSummaryFromShellScript(cmd)
 	})
 }
@@ -73,3 +117,80 @@ func (cmd *shellScriptCommand) Summary() (string, error) {
 func (cmd *shellScriptCommand) Help() (string, error) {
 	return readHelpFromShellScript(cmd)
 }
+
+// Complete answers completions declared with '# COMPLETE:' without executing the
+// script. Scripts without such a comment are executed with --complete.
+func (cmd *shellScriptCommand) Complete(e *Entrypoint, args, env []string) ([]string, shellcomp.Directive, error) {
+	return cmd.CompleteContext(context.Background(), e, args, env)
+}
+
+func (cmd *shellScriptCommand) CompleteContext(ctx context.Context, e *Entrypoint, args, env []string) ([]string, shellcomp.Directive, error) {
+	if complete := cmd.readMetadata().complete; complete != nil {
+		completions, directive := staticCompletions(*complete, args)
+		return completions, directive, nil
+	}
+	return cmd.executableCommand.CompleteContext(ctx, e, args, env)
+}
+
+func (cmd *shellScriptCommand) Aliases() []string { return cmd.readMetadata().aliases }
+func (cmd *shellScriptCommand) Hidden() bool      { return cmd.readMetadata().hidden }
+func (cmd *shellScriptCommand) Heading() string   { return cmd.readMetadata().heading }
+func (cmd *shellScriptCommand) isDefault() bool   { return cmd.readMetadata().isDefault }
+
+func (cmd *shellScriptCommand) Deprecated() (string, bool) {
+	if deprecated := cmd.readMetadata().deprecated; deprecated != nil {
+		return *deprecated, true
+	}
+	return "", false
+}
+
+// readMetadata reads the script's magic comments once. A script that can't be
+// read is treated as having none.
+func (cmd *shellScriptCommand) readMetadata() *scriptMetadata {
+	cmd.metadataOnce.Do(func() {
+		if f, err := cmd.open(); err == nil {
+			defer f.Close()
+			cmd.metadata, _ = getMetadataFromMagicComments(f, cmd.commentLeader)
+		}
+	})
+	return &cmd.metadata
+}
+
+// staticCompletions returns the completions for the last of the given args
+// declared by a '# COMPLETE:' magic comment.
+func staticCompletions(spec string, args []string) ([]string, shellcomp.Directive) {
+	switch {
+	case spec == "files":
+		return nil, shellcomp.DirectiveDefault
+	case spec == "dirs":
+		return nil, shellcomp.DirectiveFilterDirs
+	case spec == "none":
+		return nil, shellcomp.DirectiveNoFileComp
+	case strings.HasPrefix(spec, "ext:"):
+		var exts []string
+		for _, ext := range splitWords(strings.TrimPrefix(spec, "ext:")) {
+			exts = append(exts, strings.TrimPrefix(ext, "."))
+		}
+		return exts, shellcomp.DirectiveFilterFileExt
+	}
+
+	var toComplete string
+	if len(args) > 0 {
+		toComplete = args[len(args)-1]
+	}
+
+	var completions []string
+	for _, word := range splitWords(spec) {
+		if strings.HasPrefix(word, toComplete) {
+			completions = append(completions, word)
+		}
+	}
+	return completions, shellcomp.DirectiveNoFileComp
+}
+
+// splitWords splits a list of words separated by commas and/or whitespace.
+func splitWords(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+}
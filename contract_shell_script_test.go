@@ -0,0 +1,222 @@
+package exoskeleton
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/square/exoskeleton/v2/pkg/shellcomp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShellScriptContractInfersCommentLeader(t *testing.T) {
+	contract := &ShellScriptContract{CommentLeaders: map[string]string{"custom": "%"}}
+	d := &discoverer{maxDepth: -1, executor: defaultExecutor, cache: nullCache{}}
+
+	scenarios := []struct {
+		file    string
+		summary string
+	}{
+		{"hello-node", "Says hello from Node"},
+		{"hello-lua", "Says hello from Lua"},
+		{"hello-clojure", "Says hello from Clojure"},
+		{"custom.js", "Says hello from an unrecognized interpreter"},
+		{"custom-leader", "Says hello in a language with a custom comment leader"},
+	}
+
+	for _, s := range scenarios {
+		path := filepath.Join(fixtures, "languages", s.file)
+		info, err := os.Lstat(path)
+		assert.NoError(t, err)
+
+		cmd, err := contract.BuildCommand(path, fs.FileInfoToDirEntry(info), nil, d)
+		assert.NoErrorf(t, err, "Given file=%s", s.file)
+
+		summary, err := cmd.Summary()
+		assert.NoErrorf(t, err, "Given file=%s", s.file)
+		assert.Equalf(t, s.summary, summary, "Given file=%s", s.file)
+	}
+}
+
+func TestShellScriptContractReadsHelpInTheLanguagesSyntax(t *testing.T) {
+	contract := &ShellScriptContract{}
+	d := &discoverer{maxDepth: -1, executor: defaultExecutor, cache: nullCache{}}
+
+	path := filepath.Join(fixtures, "languages", "hello-node")
+	info, err := os.Lstat(path)
+	assert.NoError(t, err)
+
+	cmd, err := contract.BuildCommand(path, fs.FileInfoToDirEntry(info), nil, d)
+	assert.NoError(t, err)
+
+	help, err := cmd.Help()
+	assert.NoError(t, err)
+	assert.Equal(t, "USAGE\n   hello-node", help)
+}
+
+func TestMagicCommentsInLisps(t *testing.T) {
+	script := "#!/usr/bin/env bb\n;; SUMMARY: Says hello\n;;; ALIASES: hi\n;; HELP: Says hello\n;;\n;; to everyone\n\n(println \"hello\")\n"
+
+	summary, err := getMessageFromMagicComments(strings.NewReader(script), "summary", ";")
+	assert.NoError(t, err)
+	assert.Equal(t, "Says hello", summary)
+
+	help, err := getMessageFromMagicComments(strings.NewReader(script), "help", ";")
+	assert.NoError(t, err)
+	assert.Equal(t, "Says hello\n\nto everyone", help)
+
+	metadata, err := getMetadataFromMagicComments(strings.NewReader(script), ";")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"hi"}, metadata.aliases)
+}
+
+func TestCommentLeaderForPrefersOverrides(t *testing.T) {
+	overrides := map[string]string{".lsp": "%", "node": "#"}
+
+	assert.Equal(t, "%", commentLeaderFor("#!/usr/bin/env sbcl\n", "hello.lsp", overrides))
+	assert.Equal(t, "#", commentLeaderFor("#!/usr/bin/env node\n", "hello.lsp", overrides))
+	assert.Equal(t, ";", commentLeaderFor("#!/usr/bin/env sbcl\n", "hello", overrides))
+	assert.Equal(t, "//", commentLeaderFor("", "hello.js", overrides))
+}
+
+func TestInterpreterFromShebang(t *testing.T) {
+	scenarios := map[string]string{
+		"#!/bin/sh\n":                     "sh",
+		"#!/usr/bin/env node\n":           "node",
+		"#!/usr/bin/env -S deno run -A\n": "deno",
+		"#!/usr/bin/env FOO=1 python3\n":  "python3",
+		"#! /bin/bash -e\n":               "bash",
+		"#!\n":                            "",
+		"not a shebang":                   "",
+	}
+
+	for shebang, expected := range scenarios {
+		assert.Equal(t, expected, interpreterFromShebang(shebang), "Given shebang=%q", shebang)
+	}
+}
+
+func TestShellScriptContractReadsMetadataFromMagicComments(t *testing.T) {
+	entrypoint, err := New([]string{filepath.Join(fixtures, "magic-comments")})
+	assert.NoError(t, err)
+
+	// Aliases are found without executing the script
+	deploy := entrypoint.cmds.Find("ship")
+	if assert.NotNil(t, deploy) {
+		assert.Equal(t, "deploy", deploy.Name())
+		assert.Equal(t, []string{"d", "ship"}, deploy.Aliases())
+	}
+
+	message, deprecated := entrypoint.cmds.Find("old").(DeprecationReporter).Deprecated()
+	assert.True(t, deprecated)
+	assert.Equal(t, "use deploy instead", message)
+
+	_, deprecated = deploy.(DeprecationReporter).Deprecated()
+	assert.False(t, deprecated)
+
+	assert.True(t, IsHidden(entrypoint.cmds.Find("secret")))
+	assert.False(t, IsHidden(deploy))
+
+	db := entrypoint.cmds.Find("db")
+	if assert.NotNil(t, db) && assert.NotNil(t, db.DefaultSubcommand()) {
+		assert.Equal(t, "status", db.DefaultSubcommand().Name())
+	}
+
+	// Hidden commands are omitted from menus; declared headings are honoured
+	menu, errs := MenuFor(entrypoint, &MenuOptions{Theme: &PlainTheme})
+	assert.Empty(t, errs)
+	assert.Equal(t, `COMMANDS
+   db:     Database tools
+   old     Deploys a service the old way
+
+RELEASE
+   deploy  Deploys a service`, sections(menu))
+
+	// Hidden commands are omitted from completions but can still be run
+	completions, _, err := entrypoint.cmds.completionsFor([]string{""})
+	assert.NoError(t, err)
+	assert.NotContains(t, completions, "secret")
+	assert.Contains(t, completions, "deploy")
+
+	cmd, _, err := entrypoint.Identify([]string{"secret"})
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", cmd.Name())
+}
+
+func TestGetMetadataFromMagicComments(t *testing.T) {
+	metadata, err := getMetadataFromMagicComments(strings.NewReader(`-- SUMMARY: Summary
+-- ALIASES: a,  b ,
+-- HIDDEN
+-- HEADING: Tools
+-- DEPRECATED
+-- HELP: DEFAULT is not a directive here
+`), "--")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, metadata.aliases)
+	assert.True(t, metadata.hidden)
+	assert.Equal(t, "Tools", metadata.heading)
+	if assert.NotNil(t, metadata.deprecated) {
+		assert.Equal(t, "", *metadata.deprecated)
+	}
+	assert.False(t, metadata.isDefault)
+}
+
+func TestGetMetadataFromMagicCommentsOnlyReadsTheHeader(t *testing.T) {
+	metadata, err := getMetadataFromMagicComments(strings.NewReader(`#!/usr/bin/env node
+
+// ALIASES: a
+// HELP: Usage:
+// HIDDEN is not a directive in the body of the help
+
+// HEADING: Tools
+console.log("hello")
+// DEFAULT
+`), "//")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a"}, metadata.aliases)
+	assert.False(t, metadata.hidden)
+	assert.Equal(t, "Tools", metadata.heading)
+	assert.False(t, metadata.isDefault)
+}
+
+func TestShellScriptCompletesFromMagicComments(t *testing.T) {
+	contract := &ShellScriptContract{}
+	d := &discoverer{maxDepth: -1, executor: defaultExecutor, cache: nullCache{}}
+
+	path := filepath.Join(fixtures, "completions", "service")
+	info, err := os.Lstat(path)
+	assert.NoError(t, err)
+
+	cmd, err := contract.BuildCommand(path, fs.FileInfoToDirEntry(info), nil, d)
+	assert.NoError(t, err)
+
+	completions, directive, err := cmd.Complete(nil, []string{"st"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"start", "stop", "status"}, completions)
+	assert.Equal(t, shellcomp.DirectiveNoFileComp, directive)
+}
+
+func TestStaticCompletions(t *testing.T) {
+	scenarios := []struct {
+		spec        string
+		args        []string
+		completions []string
+		directive   shellcomp.Directive
+	}{
+		{"files", []string{""}, nil, shellcomp.DirectiveDefault},
+		{"dirs", []string{""}, nil, shellcomp.DirectiveFilterDirs},
+		{"none", []string{""}, nil, shellcomp.DirectiveNoFileComp},
+		{"ext:yaml,yml", []string{""}, []string{"yaml", "yml"}, shellcomp.DirectiveFilterFileExt},
+		{"ext: .yaml, .yml", []string{""}, []string{"yaml", "yml"}, shellcomp.DirectiveFilterFileExt},
+		{"start, stop, status", []string{"sta"}, []string{"start", "status"}, shellcomp.DirectiveNoFileComp},
+		{"start stop status", []string{"start", ""}, []string{"start", "stop", "status"}, shellcomp.DirectiveNoFileComp},
+		{"start stop status", []string{"x"}, nil, shellcomp.DirectiveNoFileComp},
+	}
+
+	for _, s := range scenarios {
+		completions, directive := staticCompletions(s.spec, s.args)
+		assert.Equal(t, s.completions, completions, "Given spec=%q, args=%v", s.spec, s.args)
+		assert.Equal(t, s.directive, directive, "Given spec=%q, args=%v", s.spec, s.args)
+	}
+}
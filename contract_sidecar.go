@@ -0,0 +1,161 @@
+package exoskeleton
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/block/opencli-go"
+	"github.com/square/exoskeleton/v2/pkg/shellcomp"
+)
+
+const defaultSidecarSuffix = ".exoskeleton.json"
+
+// SidecarContract handles executables that are described by a sidecar file
+// beside them, so that tools which don't respond to --summary (like third-party
+// binaries) can be listed without wrapper scripts.
+//
+// The sidecar for 'kubectl' is 'kubectl.exoskeleton.json'. It contains an
+// OpenCLI command (github.com/block/opencli-go) along with the command's help
+// and how its arguments are completed:
+//
+//	{
+//	  "summary": "Controls Kubernetes clusters",
+//	  "aliases": ["k"],
+//	  "help": "USAGE\n   kubectl <command>",
+//	  "complete": "files",
+//	  "options": [{"name": "--namespace", "aliases": ["-n"]}]
+//	}
+//
+// "complete" accepts the same values as the '# COMPLETE:' magic comment (see
+// ShellScriptContract). Options are completed when the argument being completed
+// starts with a dash. Executables with a sidecar are never executed to obtain
+// their summary or completions; they are executed with --help only if their
+// sidecar doesn't provide help.
+//
+// Sidecars must provide a summary. Sidecar files themselves are ignored.
+type SidecarContract struct {
+	// Suffix is appended to an executable's name to find its sidecar.
+	// (Default: ".exoskeleton.json")
+	Suffix string
+}
+
+type sidecarDescriptor struct {
+	Help     *string `json:"help,omitempty"`
+	Complete *string `json:"complete,omitempty"`
+}
+
+func (c *SidecarContract) BuildCommand(path string, info fs.DirEntry, parent Command, d DiscoveryContext) (Command, error) {
+	// Only applies to files
+	if info.IsDir() {
+		return nil, notApplicable("is a directory")
+	}
+
+	// Sidecars are not commands
+	if strings.HasSuffix(path, c.suffix()) {
+		return nil, nil
+	}
+
+	// Must be executable
+	if ok, err := isExecutable(info); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, notApplicable("not executable")
+	}
+
+	// Must have a sidecar
+	b, err := fs.ReadFile(d.FS(), path+c.suffix())
+	if err != nil {
+		return nil, notApplicable("no sidecar")
+	}
+
+	var sidecar sidecarDescriptor
+	var cmd opencli.Command
+	if err := json.Unmarshal(b, &sidecar); err != nil {
+		return nil, fmt.Errorf("error parsing sidecar: %w", err)
+	}
+	if err := json.Unmarshal(b, &cmd); err != nil {
+		return nil, fmt.Errorf("error parsing sidecar: %w", err)
+	}
+	cmd.Commands = nil
+
+	// Commands without a summary aren't listed in menus, so require one rather
+	// than leave the command out without saying why
+	if cmd.Summary == nil {
+		return nil, fmt.Errorf("error parsing sidecar: %s has no \"summary\"", path+c.suffix())
+	}
+
+	return &sidecarCommand{
+		executableCommand: executableCommand{
+			parent:       parent,
+			path:         path,
+			name:         filepath.Base(path),
+			aliases:      cmd.Aliases,
+			summary:      cmd.Summary,
+			discoveredIn: dirPath(d.FS(), path),
+			executor:     d.Executor(),
+			cache:        d.Cache(),
+			fsys:         d.FS(),
+			openCLI:      &cmd,
+			contract:     "Sidecar",
+		},
+		help:     sidecar.Help,
+		complete: sidecar.Complete,
+	}, nil
+}
+
+func (c *SidecarContract) suffix() string {
+	if c.Suffix == "" {
+		return defaultSidecarSuffix
+	}
+	return c.Suffix
+}
+
+// sidecarCommand implements the Command interface for an executable described
+// by a sidecar file. It extends executableCommand but answers Help() and
+// Complete() from the sidecar.
+type sidecarCommand struct {
+	executableCommand
+	help     *string
+	complete *string
+}
+
+func (cmd *sidecarCommand) Help() (string, error) {
+	if cmd.help != nil {
+		return *cmd.help, nil
+	}
+	return readHelpFromExecutable(&cmd.executableCommand)
+}
+
+func (cmd *sidecarCommand) Complete(_ *Entrypoint, args, _ []string) ([]string, shellcomp.Directive, error) {
+	var toComplete string
+	if len(args) > 0 {
+		toComplete = args[len(args)-1]
+	}
+
+	if strings.HasPrefix(toComplete, "-") && len(cmd.openCLI.Options) > 0 {
+		var completions []string
+		for _, option := range cmd.openCLI.Options {
+			for _, name := range append([]string{option.Name}, option.Aliases...) {
+				if strings.HasPrefix(name, toComplete) {
+					completions = append(completions, name)
+				}
+			}
+		}
+		return completions, shellcomp.DirectiveNoFileComp, nil
+	}
+
+	if cmd.complete != nil {
+		completions, directive := staticCompletions(*cmd.complete, args)
+		return completions, directive, nil
+	}
+
+	return nil, shellcomp.DirectiveDefault, nil
+}
+
+func (cmd *sidecarCommand) CompleteContext(_ context.Context, e *Entrypoint, args, env []string) ([]string, shellcomp.Directive, error) {
+	return cmd.Complete(e, args, env)
+}
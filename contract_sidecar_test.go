@@ -0,0 +1,60 @@
+package exoskeleton
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/square/exoskeleton/v2/pkg/shellcomp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSidecarContractDescribesExecutables(t *testing.T) {
+	d := &discoverer{maxDepth: -1, executor: defaultExecutor, cache: nullCache{}, contracts: []Contract{&SidecarContract{}, &StandaloneExecutableContract{}}}
+	cmds, errs := d.DiscoverIn(filepath.Join(fixtures, "sidecar"), nil)
+
+	// Sidecars without a summary are reported (rather than silently left out of menus)
+	if assert.Len(t, errs, 1) {
+		assert.ErrorContains(t, errs[0], `unsummarized.exoskeleton.json has no "summary"`)
+	}
+
+	// Sidecars themselves aren't discovered
+	assert.Equal(t, "tool\nundocumented", namesOf(cmds))
+
+	tool := cmds.Find("t")
+	if !assert.NotNil(t, tool) {
+		return
+	}
+	assert.Equal(t, "Sidecar", tool.(ContractReporter).Contract())
+
+	summary, err := tool.Summary()
+	assert.NoError(t, err)
+	assert.Equal(t, "A third-party tool", summary)
+
+	help, err := tool.Help()
+	assert.NoError(t, err)
+	assert.Equal(t, "USAGE\n   tool [--verbose] <file>", help)
+
+	openCLI, err := tool.(OpenCLIDescriber).OpenCLICommand()
+	assert.NoError(t, err)
+	assert.Len(t, openCLI.Options, 2)
+
+	completions, directive, err := tool.Complete(nil, []string{"--ver"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"--verbose", "--version"}, completions)
+	assert.Equal(t, shellcomp.DirectiveNoFileComp, directive)
+
+	completions, directive, err = tool.Complete(nil, []string{"--verbose", ""}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"yaml", "yml"}, completions)
+	assert.Equal(t, shellcomp.DirectiveFilterFileExt, directive)
+
+	// Falls back to --help when the sidecar doesn't provide help
+	help, err = cmds.Find("undocumented").Help()
+	assert.NoError(t, err)
+	assert.Equal(t, "tool [--verbose] <file>", help)
+
+	completions, directive, err = cmds.Find("undocumented").Complete(nil, []string{""}, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, completions)
+	assert.Equal(t, shellcomp.DirectiveDefault, directive)
+}
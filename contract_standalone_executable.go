@@ -1,6 +1,9 @@
 package exoskeleton
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"io/fs"
 	"path/filepath"
 )
@@ -14,32 +17,39 @@ type StandaloneExecutableContract struct{}
 func (c *StandaloneExecutableContract) BuildCommand(path string, info fs.DirEntry, parent Command, d DiscoveryContext) (Command, error) {
 	// Only applies to files
 	if info.IsDir() {
-		return nil, ErrNotApplicable
+		return nil, notApplicable("is a directory")
 	}
 
 	// Must be executable
 	if ok, err := isExecutable(info); err != nil {
 		return nil, err
 	} else if !ok {
-		return nil, ErrNotApplicable
+		return nil, notApplicable("not executable")
 	}
 
 	cmd := &executableCommand{
 		parent:       parent,
 		path:         path,
 		name:         filepath.Base(path),
-		discoveredIn: filepath.Dir(path),
+		discoveredIn: dirPath(d.FS(), path),
 		executor:     d.Executor(),
 		cache:        d.Cache(),
+		fsys:         d.FS(),
 		contract:     "StandaloneExecutable",
 	}
 
-	// Only applies to executables that define a summary
-	summary, err := d.Cache().Fetch(cmd, "summary", func() (string, error) {
+	// Only applies to executables that define a summary. Executables that don't
+	// respond in time are kept (without a summary) so that menus can list them as
+	// unavailable.
+	summary, err := fetch(context.Background(), d.Cache(), cmd, "summary", func(context.Context) (string, error) {
 		return readSummaryFromExecutable(cmd)
 	})
-	if err != nil || summary == "" {
-		return nil, ErrNotApplicable
+	if errors.As(err, &CommandTimeoutError{}) {
+		return cmd, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("%w: --summary failed: %w", ErrNotApplicable, err)
+	} else if summary == "" {
+		return nil, notApplicable("no summary")
 	}
 	cmd.summary = &summary
 
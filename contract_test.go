@@ -203,9 +203,9 @@ func TestContractOrder(t *testing.T) {
 	}
 	entry := fs.FileInfoToDirEntry(info)
 
-	_, err = d.buildCommand(fixtures, nil, entry)
+	_, err = d.buildCommands(fixtures, nil, entry, nil)
 	if err != nil {
-		t.Fatalf("buildCommand failed: %v", err)
+		t.Fatalf("buildCommands failed: %v", err)
 	}
 
 	// Verify first contract was called
@@ -236,6 +236,7 @@ func (c *testContract) BuildCommand(path string, info fs.DirEntry, parent Comman
 func defaultContracts() []Contract {
 	return []Contract{
 		&DirectoryContract{MetadataFilename: ".exoskeleton"},
+		&SidecarContract{},
 		&ExecutableContract{},
 		&ShellScriptContract{},
 		&StandaloneExecutableContract{},
@@ -1,7 +1,10 @@
 package exoskeleton
 
 import (
-	"path/filepath"
+	"context"
+	"io"
+	"slices"
+	"sync"
 
 	"github.com/square/exoskeleton/v2/pkg/shellcomp"
 )
@@ -10,9 +13,20 @@ type directoryCommand struct {
 	executableCommand
 	cmds       Commands
 	discoverer DiscoveryContext
+
+	discoverOnce sync.Once
+	errs         []error
+
+	metadataOnce sync.Once
+	metadata     *moduleMetadata
+	metadataErr  error
 }
 
 func (m *directoryCommand) Exec(e *Entrypoint, args, env []string) error {
+	return m.ExecContext(context.Background(), e, args, env)
+}
+
+func (m *directoryCommand) ExecContext(_ context.Context, e *Entrypoint, args, env []string) error {
 	return e.printModuleHelp(m, args)
 }
 
@@ -20,23 +34,120 @@ func (m *directoryCommand) Complete(_ *Entrypoint, args, _ []string) ([]string,
 	return completionsForSubcommands(m, args)
 }
 
+func (m *directoryCommand) CompleteContext(_ context.Context, e *Entrypoint, args, env []string) ([]string, shellcomp.Directive, error) {
+	return m.Complete(e, args, env)
+}
+
 func (m *directoryCommand) Summary() (string, error) {
-	return m.cache.Fetch(m, "summary", func() (string, error) {
+	return fetch(context.Background(), m.cache, m, "summary", func(context.Context) (string, error) {
 		return readSummaryFromModulefile(m)
 	})
 }
 
+// Help returns the module's description from its metadata file. (Help for
+// modules is otherwise a menu of their subcommands.)
 func (m *directoryCommand) Help() (string, error) {
-	panic("Unused")
+	return m.description(), nil
 }
 
-func (m *directoryCommand) DefaultSubcommand() Command { return nil }
+// Aliases returns the aliases declared in the module's metadata file.
+func (m *directoryCommand) Aliases() []string {
+	if metadata, err := m.readMetadata(); err == nil {
+		return metadata.Aliases
+	}
+	return nil
+}
 
-func (m *directoryCommand) Subcommands() (Commands, error) {
-	if m.cmds == nil && m.discoverer != nil {
-		m.cmds, _ = m.discoverer.DiscoverIn(filepath.Dir(m.path), m)
-		// TODO: return errors from discovery
+// DefaultSubcommand returns the subcommand named by 'defaultCommand' in the
+// module's metadata file or else the first subcommand that declares itself to
+// be the module's default (e.g. a script with the magic comment '# DEFAULT').
+func (m *directoryCommand) DefaultSubcommand() Command {
+	cmds, err := m.Subcommands()
+	if err != nil && !isPartial(err) {
+		return nil
 	}
+	if metadata, err := m.readMetadata(); err == nil && metadata.DefaultCommand != "" {
+		return cmds.Find(metadata.DefaultCommand)
+	}
+	for _, cmd := range cmds {
+		if d, ok := cmd.(defaulter); ok && d.isDefault() {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// defaulter is implemented by Commands that can declare themselves to be the
+// default subcommand of their module.
+type defaulter interface {
+	isDefault() bool
+}
 
+// Subcommands discovers the commands in the module's directory. If some of
+// them can't be discovered, it returns the rest with a PartialDiscoveryError.
+func (m *directoryCommand) Subcommands() (Commands, error) {
+	m.discoverOnce.Do(func() {
+		if m.cmds == nil && m.discoverer != nil {
+			m.cmds, m.errs = m.discoverer.DiscoverIn(dirPath(m.fsys, m.path), m)
+		}
+	})
+
+	if len(m.errs) > 0 {
+		return m.cmds, PartialDiscoveryError{Path: dirPath(m.fsys, m.path), Errors: m.errs}
+	}
 	return m.cmds, nil
 }
+
+func (m *directoryCommand) subcommandsContext(_ context.Context) (Commands, error) {
+	return m.Subcommands()
+}
+
+func (m *directoryCommand) description() string {
+	if metadata, err := m.readMetadata(); err == nil {
+		return metadata.Description
+	}
+	return ""
+}
+
+func (m *directoryCommand) hidesSubcommand(cmd Command) bool {
+	metadata, err := m.readMetadata()
+	return err == nil && slices.Contains(metadata.Hidden, cmd.Name())
+}
+
+func (m *directoryCommand) subcommandOrder() []string {
+	if metadata, err := m.readMetadata(); err == nil {
+		return metadata.Order
+	}
+	return nil
+}
+
+// readMetadata reads and parses the module's metadata file once. If it can't,
+// the error is reported to the Entrypoint's ErrorCallbacks (once, since most
+// accessors fall back to defaults rather than return it) as a DiscoveryError.
+func (m *directoryCommand) readMetadata() (*moduleMetadata, error) {
+	m.metadataOnce.Do(func() {
+		m.metadata, m.metadataErr = m.parseMetadata()
+		if m.metadataErr == nil {
+			return
+		}
+		m.metadataErr = DiscoveryError{Cause: m.metadataErr, Path: m.path}
+		if e := entrypointOf(m); e != nil {
+			e.onError(m.metadataErr)
+		}
+	})
+	return m.metadata, m.metadataErr
+}
+
+func (m *directoryCommand) parseMetadata() (*moduleMetadata, error) {
+	f, err := m.open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	b, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	return parseModuleMetadata(b)
+}
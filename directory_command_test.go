@@ -0,0 +1,103 @@
+package exoskeleton
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirectoryCommandReadsStructuredMetadata(t *testing.T) {
+	entrypoint, err := New([]string{filepath.Join(fixtures, "structured-module")}, WithName("e"))
+	assert.NoError(t, err)
+
+	db := entrypoint.cmds.Find("database")
+	if !assert.NotNil(t, db) {
+		return
+	}
+	assert.Equal(t, "db", db.Name())
+
+	summary, err := db.Summary()
+	assert.NoError(t, err)
+	assert.Equal(t, "Database tools", summary)
+
+	help, err := db.Help()
+	assert.NoError(t, err)
+	assert.Equal(t, "Tools for managing the database.\n\nRun migrations before deploying!", help)
+
+	// The default command is used when no subcommand is given
+	cmd, rest, err := entrypoint.Identify([]string{"db", "--verbose"})
+	assert.NoError(t, err)
+	assert.Equal(t, "status", cmd.Name())
+	assert.Equal(t, []string{"--verbose"}, rest)
+
+	// Hidden commands can still be run
+	cmd, _, err = entrypoint.Identify([]string{"db", "repair"})
+	assert.NoError(t, err)
+	assert.Equal(t, "repair", cmd.Name())
+
+	// Modules can be described with JSON, too
+	summary, err = entrypoint.cmds.Find("c").Summary()
+	assert.NoError(t, err)
+	assert.Equal(t, "Cache tools", summary)
+
+	menu, errs := MenuFor(db, &MenuOptions{Theme: &PlainTheme})
+	assert.Empty(t, errs)
+	assert.Equal(t, `USAGE
+   e db <command> [<args>]
+
+Tools for managing the database.
+
+Run migrations before deploying!
+
+COMMANDS
+   status   Shows the status of migrations
+   migrate  Runs migrations
+   backup   Backs up the database
+
+Run e help db <command> to print information on a specific command.`, menu)
+
+	completions, _, err := db.Complete(entrypoint, []string{""}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"backup", "migrate", "status"}, completions)
+}
+
+func TestDirectoryCommandReadsMagicComments(t *testing.T) {
+	entrypoint, err := New([]string{fixtures})
+	assert.NoError(t, err)
+
+	summary, err := entrypoint.cmds.Find("go").Summary()
+	assert.NoError(t, err)
+	assert.Equal(t, "Provides several commands", summary)
+	assert.Empty(t, entrypoint.cmds.Find("go").Aliases())
+}
+
+func TestDirectoryCommandReportsInvalidMetadataOnce(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "tools"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tools", ".exoskeleton"), []byte(`{"summary": `), 0644))
+
+	var errs []error
+	entrypoint, err := New([]string{dir}, WithName("e"), OnError(func(_ *Entrypoint, err error) {
+		errs = append(errs, err)
+	}))
+	require.NoError(t, err)
+
+	tools := entrypoint.cmds.Find("tools")
+	require.NotNil(t, tools)
+
+	_, err = tools.Summary()
+	assert.ErrorAs(t, err, &CommandSummaryError{})
+	assert.Empty(t, tools.Aliases())
+	assert.Nil(t, tools.(*directoryCommand).DefaultSubcommand())
+	help, err := tools.Help()
+	assert.NoError(t, err)
+	assert.Empty(t, help)
+
+	if assert.Len(t, errs, 1, "should report the invalid metadata once") {
+		assert.ErrorAs(t, errs[0], &DiscoveryError{})
+		assert.Contains(t, errs[0].Error(), "error parsing module metadata")
+	}
+}
@@ -1,10 +1,14 @@
 package exoskeleton
 
 import (
+	"context"
 	"errors"
 	"io/fs"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 )
 
 type discoverer struct {
@@ -13,6 +17,12 @@ type discoverer struct {
 	executor  ExecutorFunc
 	contracts []Contract
 	cache     Cache
+	fsys      fs.FS
+	logger    *slog.Logger
+
+	// report records the outcome of discovery when it is set
+	report   *DiscoveryReport
+	reportMu *sync.Mutex
 }
 
 type DiscoveryContext interface {
@@ -21,6 +31,11 @@ type DiscoveryContext interface {
 	MaxDepth() int
 	Next() DiscoveryContext
 	Cache() Cache
+
+	// FS returns the file system in which commands are being discovered.
+	// Paths given to a Contract are paths within this file system. When
+	// discovering commands on disk, it accepts native paths like package os.
+	FS() fs.FS
 }
 
 func (d *discoverer) Next() DiscoveryContext {
@@ -30,24 +45,101 @@ func (d *discoverer) Next() DiscoveryContext {
 		executor:  d.executor,
 		contracts: d.contracts,
 		cache:     d.cache,
+		fsys:      d.fsys,
+		logger:    d.logger,
+		report:    d.report,
+		reportMu:  d.reportMu,
 	}
 }
 
 var _ DiscoveryContext = &discoverer{}
 
 func (e *Entrypoint) discoverIn(paths []string) Commands {
-	all := Commands{}
-	d := &discoverer{
+	return e.discoverWith(e.newDiscoverer(), paths)
+}
+
+func (e *Entrypoint) newDiscoverer() *discoverer {
+	return &discoverer{
 		onError:   e.onError,
 		executor:  e.executor,
 		maxDepth:  e.maxDepth,
 		contracts: e.contracts,
 		cache:     e.cache,
+		logger:    e.logger,
 	}
+}
+
+func (e *Entrypoint) discoverWith(d *discoverer, paths []string) Commands {
+	all := Commands{}
 	for _, path := range paths {
 		cmds, _ := d.DiscoverIn(path, e)
 		all = append(all, cmds...)
 	}
+	for _, source := range e.fsSources {
+		fd := *d
+		fd.fsys = source.fsys
+		cmds, _ := fd.DiscoverIn(source.path, e)
+		all = append(all, cmds...)
+	}
+	if e.searchPATH {
+		all = append(all, d.discoverOnPATH(os.Getenv("PATH"), e.name+"-", e)...)
+	}
+	return all
+}
+
+// discoverOnPATH discovers commands among the files in the directories listed in
+// pathEnv whose names begin with prefix (the way git runs `git-foo` for `git foo`).
+// The prefix is removed from the names of the commands that are built.
+//
+// Directories listed earlier take precedence: commands are returned in the order
+// their directories are listed, so a command found in an earlier directory shadows
+// commands with the same name in later ones (which `which --all` lists).
+func (d *discoverer) discoverOnPATH(pathEnv, prefix string, parent Command) Commands {
+	all := Commands{}
+
+	for _, dir := range filepath.SplitList(pathEnv) {
+		if dir == "" {
+			continue
+		}
+
+		// It's common for $PATH to list directories that don't exist
+		files, err := fs.ReadDir(d.FS(), dir)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+
+		report := d.reportDirectory(dir, parent)
+		if err != nil {
+			if d.onError != nil {
+				d.onError(DiscoveryError{Cause: err, Path: dir})
+			}
+			if report != nil {
+				report.Err = DiscoveryError{Cause: err, Path: dir}
+			}
+		}
+
+		for _, file := range files {
+			if !strings.HasPrefix(file.Name(), prefix) || file.Name() == prefix {
+				continue
+			}
+
+			cmds, err := d.buildCommands(dir, parent, file, report)
+			if err != nil {
+				if d.onError != nil {
+					d.onError(err)
+				}
+				continue
+			}
+
+			for _, cmd := range cmds {
+				if r, ok := cmd.(renamer); ok {
+					r.rename(strings.TrimPrefix(cmd.Name(), prefix))
+				}
+				all = append(all, cmd)
+			}
+		}
+	}
+
 	return all
 }
 
@@ -59,61 +151,127 @@ func (d *discoverer) Cache() Cache {
 	}
 	return d.cache
 }
+func (d *discoverer) log() *slog.Logger {
+	if d.logger == nil {
+		return discardLogger
+	}
+	return d.logger
+}
+func (d *discoverer) FS() fs.FS {
+	if d.fsys == nil {
+		return osFS{}
+	}
+	return d.fsys
+}
 
 func (d *discoverer) DiscoverIn(path string, parent Command) (Commands, []error) {
 	var all Commands
 	var errs []error
 
-	files, err := os.ReadDir(path)
+	report := d.reportDirectory(path, parent)
+	files, err := fs.ReadDir(d.FS(), path)
 	if err != nil {
 		if d.onError != nil {
 			d.onError(err)
 		}
 		errs = append(errs, DiscoveryError{Cause: err, Path: path})
+		if report != nil {
+			report.Err = errs[0]
+		}
 		// No return. We may have a partial list of files: "ReadDir returns the entries
 		// it was able to read before the error, along with the error"
 	}
 
 	for _, file := range files {
-		if cmd, err := d.buildCommand(path, parent, file); err != nil {
+		if !isOS(d.fsys) {
+			file = fsEntry{file, d.fsys, joinPath(d.fsys, path, file.Name())}
+		}
+		if cmds, err := d.buildCommands(path, parent, file, report); err != nil {
 			if d.onError != nil {
 				d.onError(err)
 			}
 			errs = append(errs, err)
-		} else if cmd != nil {
-			all = append(all, cmd)
+		} else {
+			all = append(all, cmds...)
 		}
 	}
 
 	return all, errs
 }
 
-func (d *discoverer) buildCommand(discoveredIn string, parent Command, file fs.DirEntry) (Command, error) {
+// buildCommands builds Commands from a directory entry with the first contract
+// that applies to it. If dir is not nil, the outcome is added to it.
+func (d *discoverer) buildCommands(discoveredIn string, parent Command, file fs.DirEntry, dir *DirectoryReport) (Commands, error) {
 	name := file.Name()
-	path := filepath.Join(discoveredIn, name)
+	path := joinPath(d.fsys, discoveredIn, name)
+
+	entry := &EntryReport{Path: path}
+	defer d.reportEntry(dir, entry)
 
 	var err error
-	if file.Type()&fs.ModeSymlink != 0 {
+	if file.Type()&fs.ModeSymlink != 0 && isOS(d.fsys) {
 		file, err = followSymlinks(path)
 		if err != nil {
-			return nil, DiscoveryError{Cause: err, Path: path}
+			entry.Err = DiscoveryError{Cause: err, Path: path}
+			return nil, entry.Err
 		}
 	}
 
 	// Try each contract in order
+	debug := d.log().Enabled(context.Background(), slog.LevelDebug)
 	for _, contract := range d.contracts {
-		if cmd, err := contract.BuildCommand(path, file, parent, d); err == nil {
-			return cmd, nil
+		cmds, err := buildWithContract(contract, path, file, parent, d)
+		if debug {
+			d.logContractResult(path, contract, cmds, err)
+		}
+
+		if err == nil {
+			entry.Contract, entry.Commands = contract, cmds
+			return cmds, nil
 		} else if !errors.Is(err, ErrNotApplicable) {
-			return nil, DiscoveryError{Cause: err, Path: path} // Contract failed with real error
+			entry.Err = DiscoveryError{Cause: err, Path: path} // Contract failed with real error
+			return nil, entry.Err
+		} else if dir != nil {
+			entry.NotApplicable = append(entry.NotApplicable, ContractRejection{Contract: contract, Reason: err})
 		}
 		// Contract doesn't apply, try next one
 	}
 
 	// No contract applies. File is ignored.
+	d.log().Debug("no contract applies; ignoring file", "path", path)
 	return nil, nil
 }
 
+// logContractResult logs the outcome of building Commands for the file at path
+// with the given contract.
+func (d *discoverer) logContractResult(path string, contract Contract, cmds Commands, err error) {
+	log := d.log().With("path", path, "contract", contractName(contract))
+	switch {
+	case err == nil && len(cmds) == 0:
+		log.Debug("contract ignores file")
+	case err == nil:
+		log.Debug("contract applies", "commands", len(cmds))
+	case errors.Is(err, ErrNotApplicable):
+		log.Debug("contract does not apply", "reason", err)
+	default:
+		log.Debug("contract failed", "error", err)
+	}
+}
+
+// buildWithContract builds Commands with the given contract, using BuildCommands
+// for contracts that implement MultiContract.
+func buildWithContract(contract Contract, path string, file fs.DirEntry, parent Command, d DiscoveryContext) (Commands, error) {
+	if mc, ok := contract.(MultiContract); ok {
+		return mc.BuildCommands(path, file, parent, d)
+	}
+
+	if cmd, err := contract.BuildCommand(path, file, parent, d); err != nil || cmd == nil {
+		return nil, err
+	} else {
+		return Commands{cmd}, nil
+	}
+}
+
 func followSymlinks(path string) (fs.DirEntry, error) {
 	if realPath, err := filepath.EvalSymlinks(path); err != nil {
 		return nil, SymlinkError{Cause: err, Path: path}
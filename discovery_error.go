@@ -1,6 +1,10 @@
 package exoskeleton
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
 
 // DiscoveryError records an error that occurred while discovering commands in a directory.
 type DiscoveryError struct {
@@ -12,3 +16,30 @@ func (e DiscoveryError) Error() string {
 	return fmt.Sprintf("error discovering commands in %s: %s", e.Path, e.Cause)
 }
 func (e DiscoveryError) Unwrap() error { return e.Cause }
+
+// PartialDiscoveryError is returned by a module's Subcommands() along with the
+// subcommands that were discovered when discovering others failed (for example,
+// because of a broken symlink). Errors holds a DiscoveryError for each failure.
+//
+// Like errors that occur during discovery at the top level, these errors are
+// also passed to the Entrypoint's OnError callbacks when they occur.
+type PartialDiscoveryError struct {
+	Path   string
+	Errors []error
+}
+
+func (e PartialDiscoveryError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+func (e PartialDiscoveryError) Unwrap() []error { return e.Errors }
+
+// isPartial returns true if err only records that some subcommands could not be
+// discovered (in which case the subcommands that were discovered can be used).
+func isPartial(err error) bool {
+	var partial PartialDiscoveryError
+	return errors.As(err, &partial)
+}
@@ -0,0 +1,169 @@
+package exoskeleton
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// DiscoveryReport records how commands were discovered: every entry in each
+// directory that was searched (including the directories of nested modules) and
+// the outcome of trying each Contract on it. Use it to answer "why isn't my
+// command in the menu?"
+type DiscoveryReport struct {
+	Directories []*DirectoryReport
+}
+
+// DirectoryReport records the discovery of commands in a directory.
+type DirectoryReport struct {
+	// Path is the directory that was searched.
+	Path string
+
+	// Parent is the Command whose subcommands were discovered in the directory
+	// (the Entrypoint or a module).
+	Parent Command
+
+	// Err is the error, if any, that occurred reading the directory.
+	Err error
+
+	// Entries describes each entry in the directory.
+	Entries []*EntryReport
+}
+
+// EntryReport records the outcome of discovery for an entry in a directory.
+type EntryReport struct {
+	// Path is the path to the entry.
+	Path string
+
+	// NotApplicable lists the contracts that returned ErrNotApplicable, in the
+	// order they were tried, with the reason each didn't apply.
+	NotApplicable []ContractRejection
+
+	// Contract is the contract that built Commands from the entry or ignored
+	// it (by returning nil, nil). It is nil if no contract applied or if
+	// discovery failed.
+	Contract Contract
+
+	// Commands are the commands that Contract built. It is empty if the entry
+	// was ignored.
+	Commands Commands
+
+	// Err is a DiscoveryError if discovery failed (for example, with a
+	// SymlinkError for a broken symlink).
+	Err error
+}
+
+// ContractRejection records that a Contract did not apply to an entry.
+type ContractRejection struct {
+	Contract Contract
+	Reason   error
+}
+
+// Ignored returns true if a contract (or no contract) applied to the entry
+// but no commands were built from it.
+func (r *EntryReport) Ignored() bool {
+	return r.Err == nil && len(r.Commands) == 0
+}
+
+// DiscoveryReport discovers the Entrypoint's commands again, descending into
+// every module whose subcommands are discovered in a directory, and reports
+// the outcome for each directory entry it encounters. The Entrypoint's own
+// commands are not affected, and OnError callbacks are not invoked.
+func (e *Entrypoint) DiscoveryReport() *DiscoveryReport {
+	report := &DiscoveryReport{}
+
+	var walk func(cmds Commands)
+	walk = func(cmds Commands) {
+		for _, cmd := range cmds {
+			if m, ok := cmd.(*directoryCommand); ok {
+				subcmds, _ := m.Subcommands()
+				walk(subcmds)
+			}
+		}
+	}
+	walk(e.rediscover(e.cache, report))
+
+	return report
+}
+
+// rediscover discovers the Entrypoint's commands again using the given cache
+// and records the outcome in report (including the outcome of discovering the
+// subcommands of modules when their Subcommands() are called).
+func (e *Entrypoint) rediscover(cache Cache, report *DiscoveryReport) Commands {
+	d := e.newDiscoverer()
+	d.onError = nil
+	d.cache = cache
+	d.report = report
+	d.reportMu = &sync.Mutex{}
+	return e.discoverWith(d, e.paths)
+}
+
+// String renders the report for people to read. It lists the reasons contracts
+// didn't apply only for entries from which no commands were built.
+func (r *DiscoveryReport) String() string {
+	var b strings.Builder
+	for _, dir := range r.Directories {
+		fmt.Fprintf(&b, "%s (%s)\n", dir.Path, Usage(dir.Parent))
+		if dir.Err != nil {
+			fmt.Fprintf(&b, "   error: %s\n", dir.Err)
+		}
+		for _, entry := range dir.Entries {
+			fmt.Fprintf(&b, "   %s: %s\n", filepath.Base(entry.Path), entry.outcome())
+			if len(entry.Commands) > 0 {
+				continue
+			}
+			for _, rejection := range entry.NotApplicable {
+				fmt.Fprintf(&b, "      %s: %s\n", contractName(rejection.Contract), strings.TrimPrefix(rejection.Reason.Error(), ErrNotApplicable.Error()+": "))
+			}
+		}
+	}
+	return b.String()
+}
+
+func (r *EntryReport) outcome() string {
+	switch {
+	case r.Err != nil:
+		return "error: " + r.Err.Error()
+	case r.Contract == nil:
+		return "ignored (no contract applies)"
+	case len(r.Commands) == 0:
+		return "ignored by " + contractName(r.Contract)
+	default:
+		usages := make([]string, len(r.Commands))
+		for i, cmd := range r.Commands {
+			usages[i] = Usage(cmd)
+		}
+		return strings.Join(usages, ", ") + " (" + contractName(r.Contract) + ")"
+	}
+}
+
+// contractName returns the name of the Contract's type (e.g.
+// "exoskeleton.ShellScriptContract").
+func contractName(c Contract) string {
+	return strings.TrimPrefix(fmt.Sprintf("%T", c), "*")
+}
+
+// reportDirectory records that commands are being discovered in path if d is
+// producing a DiscoveryReport.
+func (d *discoverer) reportDirectory(path string, parent Command) *DirectoryReport {
+	if d.report == nil {
+		return nil
+	}
+	dir := &DirectoryReport{Path: path, Parent: parent}
+	d.reportMu.Lock()
+	defer d.reportMu.Unlock()
+	d.report.Directories = append(d.report.Directories, dir)
+	return dir
+}
+
+// reportEntry records the outcome of discovery for an entry in dir (which is
+// nil unless d is producing a DiscoveryReport).
+func (d *discoverer) reportEntry(dir *DirectoryReport, entry *EntryReport) {
+	if dir == nil {
+		return
+	}
+	d.reportMu.Lock()
+	defer d.reportMu.Unlock()
+	dir.Entries = append(dir.Entries, entry)
+}
@@ -0,0 +1,87 @@
+package exoskeleton
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscoveryReport(t *testing.T) {
+	dir := t.TempDir()
+	writeScript(t, filepath.Join(dir, "hello"), "#!/bin/sh\n# SUMMARY: Says hello\n")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README"), []byte("not a command"), 0644))
+	require.NoError(t, os.Symlink(filepath.Join(dir, "missing"), filepath.Join(dir, "broken")))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "db"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "db", ".exoskeleton"), nil, 0644))
+	writeScript(t, filepath.Join(dir, "db", "migrate"), "#!/bin/sh\n# SUMMARY: Migrates\n")
+
+	var reported []error
+	entrypoint, err := New([]string{dir}, WithName("e"), OnError(func(_ *Entrypoint, err error) { reported = append(reported, err) }))
+	require.NoError(t, err)
+	reported = nil
+
+	report := entrypoint.DiscoveryReport()
+	assert.Empty(t, reported, "should not invoke OnError callbacks")
+
+	require.Len(t, report.Directories, 2)
+	top, db := report.Directories[0], report.Directories[1]
+
+	assert.Equal(t, dir, top.Path)
+	assert.Same(t, entrypoint, top.Parent)
+	assert.NoError(t, top.Err)
+
+	entries := map[string]*EntryReport{}
+	for _, entry := range top.Entries {
+		entries[filepath.Base(entry.Path)] = entry
+	}
+	require.Len(t, entries, 4)
+
+	hello := entries["hello"]
+	assert.IsType(t, &ShellScriptContract{}, hello.Contract)
+	if assert.Len(t, hello.Commands, 1) {
+		assert.Equal(t, "e hello", Usage(hello.Commands[0]))
+	}
+	rejected := rejectedContracts(hello)
+	assert.Contains(t, rejected, "exoskeleton.DirectoryContract", "should have tried the contracts that precede ShellScriptContract")
+	assert.Contains(t, rejected, "exoskeleton.ExecutableContract", "should have tried the contracts that precede ShellScriptContract")
+	assert.NotContains(t, rejected, "exoskeleton.ShellScriptContract")
+	assert.NotContains(t, rejected, "exoskeleton.StandaloneExecutableContract", "should stop at the contract that applies")
+	for _, rejection := range hello.NotApplicable {
+		assert.ErrorIs(t, rejection.Reason, ErrNotApplicable)
+	}
+
+	readme := entries["README"]
+	assert.Nil(t, readme.Contract)
+	assert.True(t, readme.Ignored())
+	rejected = rejectedContracts(readme)
+	for _, contract := range []string{"exoskeleton.DirectoryContract", "exoskeleton.ExecutableContract", "exoskeleton.ShellScriptContract", "exoskeleton.StandaloneExecutableContract"} {
+		assert.Contains(t, rejected, contract, "should have tried every contract")
+	}
+
+	var symlinkErr SymlinkError
+	assert.ErrorAs(t, entries["broken"].Err, &symlinkErr)
+
+	assert.IsType(t, &DirectoryContract{}, entries["db"].Contract)
+
+	assert.Equal(t, filepath.Join(dir, "db"), db.Path)
+	assert.Equal(t, "e db", Usage(db.Parent))
+	if assert.Len(t, db.Entries, 2) {
+		assert.True(t, db.Entries[0].Ignored(), "should ignore .exoskeleton")
+		assert.Equal(t, "e db migrate", Usage(db.Entries[1].Commands[0]))
+	}
+
+	assert.Contains(t, report.String(), "   hello: e hello (exoskeleton.ShellScriptContract)\n")
+	assert.Contains(t, report.String(), "   README: ignored (no contract applies)\n      exoskeleton.DirectoryContract: not a directory\n")
+}
+
+// rejectedContracts returns the names of the contracts that didn't apply to entry.
+func rejectedContracts(entry *EntryReport) []string {
+	var names []string
+	for _, rejection := range entry.NotApplicable {
+		names = append(names, contractName(rejection.Contract))
+	}
+	return names
+}
@@ -1,12 +1,20 @@
 package exoskeleton
 
 import (
+	"bytes"
+	"context"
+	"fmt"
 	"io/fs"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestDiscoverInFindsNothing(t *testing.T) {
@@ -132,8 +140,10 @@ func TestDiscovererBuildsCommand(t *testing.T) {
 					path:         filepath.Join(fixtures, "echoargs"),
 					discoveredIn: fixtures,
 					cache:        nullCache{},
+					fsys:         osFS{},
 					contract:     "ShellScript",
 				},
+				commentLeader: "#",
 			},
 		},
 		{
@@ -145,6 +155,7 @@ func TestDiscovererBuildsCommand(t *testing.T) {
 					path:         filepath.Join(fixtures, "nested-1", ".exoskeleton"),
 					discoveredIn: fixtures,
 					cache:        nullCache{},
+					fsys:         osFS{},
 					contract:     "Directory",
 				},
 				discoverer: d.Next(),
@@ -158,6 +169,7 @@ func TestDiscovererBuildsCommand(t *testing.T) {
 				path:         filepath.Join(fixtures, "go.exoskeleton"),
 				discoveredIn: fixtures,
 				cache:        nullCache{},
+				fsys:         osFS{},
 				discoverer:   d.Next(),
 				contract:     "Executable",
 			},
@@ -169,10 +181,10 @@ func TestDiscovererBuildsCommand(t *testing.T) {
 		info, err := os.Lstat(path)
 		assert.NoErrorf(t, err, "Given executable=%s", s.executable)
 		entry := fs.FileInfoToDirEntry(info)
-		cmd, err := d.buildCommand(fixtures, parent, entry)
+		cmds, err := d.buildCommands(fixtures, parent, entry, nil)
 		assert.NoErrorf(t, err, "Given executable=%s", s.executable)
 
-		assert.Equalf(t, s.expected, cmd, "Given executable=%s", s.executable)
+		assert.Equalf(t, Commands{s.expected}, cmds, "Given executable=%s", s.executable)
 	}
 }
 
@@ -187,3 +199,250 @@ func TestFollowingSymlinks(t *testing.T) {
 	cmd := cmds.Find("hello-prime")
 	assert.FileExists(t, cmd.Path())
 }
+
+func TestDiscoverInFS(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	fsys := fstest.MapFS{
+		"libexec/greet":              {Data: []byte("#!/bin/sh\n# SUMMARY: Greets someone\necho hello $1\n")},
+		"libexec/README.md":          {Data: []byte("Not a command"), Mode: 0644},
+		"libexec/NOTES":              {Data: []byte("Not a command, either")},
+		"libexec/tools/.exoskeleton": {Data: []byte("# SUMMARY: Some tools\n")},
+		"libexec/tools/lint":         {Data: []byte("#!/bin/sh\n# SUMMARY: Lints\n")},
+	}
+
+	entrypoint, err := New(nil, WithFS(fsys, "libexec"))
+	assert.NoError(t, err)
+
+	cmds, _ := entrypoint.Subcommands()
+	all, errs := cmds.Flatten()
+	assert.Empty(t, errs)
+	assert.Equal(t, "help\nwhich\ncomplete\ngreet\ntools\nlint", namesOf(all))
+
+	greet := cmds.Find("greet")
+	assert.Equal(t, "libexec/greet", greet.Path())
+
+	summary, err := greet.Summary()
+	assert.NoError(t, err)
+	assert.Equal(t, "Greets someone", summary)
+
+	summary, err = cmds.Find("tools").Summary()
+	assert.NoError(t, err)
+	assert.Equal(t, "Some tools", summary)
+
+	// Embedded executables are extracted in order to run them
+	out, err := greet.(*shellScriptCommand).Command("world").Output()
+	assert.NoError(t, err)
+	assert.Equal(t, "hello world\n", string(out))
+
+	// ...only once (rather than every time they're run)
+	fsys["libexec/greet"].Data = []byte("#!/bin/sh\necho changed\n")
+	out, err = greet.(*shellScriptCommand).Command("world").Output()
+	assert.NoError(t, err)
+	assert.Equal(t, "hello world\n", string(out))
+}
+
+func TestFSEntryReportsExecutables(t *testing.T) {
+	fsys := fstest.MapFS{
+		"script":   {Data: []byte("#!/bin/sh\n")},
+		"elf":      {Data: []byte("\x7fELF\x02\x01")},
+		"macho":    {Data: []byte("\xcf\xfa\xed\xfe\x07")},
+		"notes":    {Data: []byte("Some notes\n")},
+		"empty":    {},
+		"writable": {Data: []byte("#!/bin/sh\n"), Mode: 0644},
+	}
+
+	entries, err := fs.ReadDir(fsys, ".")
+	require.NoError(t, err)
+
+	executables := []string{}
+	for _, entry := range entries {
+		info, err := fsEntry{entry, fsys, entry.Name()}.Info()
+		require.NoError(t, err)
+		if info.Mode()&0111 != 0 {
+			executables = append(executables, entry.Name())
+		}
+	}
+	assert.Equal(t, []string{"elf", "macho", "script"}, executables)
+}
+
+func TestDiscoverInFSWithCustomContract(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.cmd": {Data: []byte("A")},
+		"b.txt": {Data: []byte("B")},
+	}
+
+	var seen []string
+	contract := &testContract{
+		matches: func(path string, info fs.DirEntry) bool {
+			seen = append(seen, path)
+			return filepath.Ext(path) == ".cmd"
+		},
+	}
+
+	d := discoverer{maxDepth: -1, contracts: []Contract{contract}, fsys: fsys}
+	_, errs := d.DiscoverIn(".", nil)
+	assert.Empty(t, errs)
+	assert.Equal(t, []string{"a.cmd", "b.txt"}, seen)
+}
+
+func TestDiscoverOnPATH(t *testing.T) {
+	first, second := t.TempDir(), t.TempDir()
+	script := "#!/bin/sh\n# SUMMARY: %s\n"
+	writeScript(t, filepath.Join(first, "e-foo"), fmt.Sprintf(script, "First foo"))
+	writeScript(t, filepath.Join(second, "e-foo"), fmt.Sprintf(script, "Second foo"))
+	writeScript(t, filepath.Join(second, "e-bar"), fmt.Sprintf(script, "Bar"))
+	writeScript(t, filepath.Join(second, "other-baz"), fmt.Sprintf(script, "Baz"))
+	writeScript(t, filepath.Join(second, "e-"), fmt.Sprintf(script, "Nameless"))
+	require.NoError(t, os.Mkdir(filepath.Join(second, "e-tools"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(second, "e-tools", ".exoskeleton"), []byte("summary: Tools\n"), 0644))
+	writeScript(t, filepath.Join(second, "e-tools", "lint"), fmt.Sprintf(script, "Lint"))
+
+	entrypoint := &Entrypoint{name: "e"}
+	d := discoverer{maxDepth: -1, contracts: defaultContracts(), cache: nullCache{}}
+	pathEnv := strings.Join([]string{filepath.Join(first, "nope"), first, second}, string(os.PathListSeparator))
+	cmds := d.discoverOnPATH(pathEnv, "e-", entrypoint)
+
+	assert.Equal(t, "foo\nbar\nfoo\ntools", namesOf(cmds))
+	assert.Equal(t, filepath.Join(first, "e-foo"), cmds.Find("foo").Path())
+	assert.Equal(t, "e bar", Usage(cmds.Find("bar")))
+
+	// Modules are renamed, too, so their subcommands can be invoked
+	tools, err := cmds.Find("tools").Subcommands()
+	assert.NoError(t, err)
+	assert.Equal(t, "e tools lint", Usage(tools.Find("lint")))
+
+	// Commands shadowed by earlier directories are kept for `which --all`
+	entrypoint.cmds = cmds
+	foos, err := namesakes(cmds.Find("foo"))
+	assert.NoError(t, err)
+	if assert.Len(t, foos, 2) {
+		assert.Equal(t, filepath.Join(first, "e-foo"), foos[0].Path())
+		assert.Equal(t, filepath.Join(second, "e-foo"), foos[1].Path())
+	}
+}
+
+func TestSearchPATHPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	writeScript(t, filepath.Join(dir, "exoskeleton.test-hello"), "#!/bin/sh\n# SUMMARY: From PATH\n")
+	writeScript(t, filepath.Join(dir, "exoskeleton.test-greet"), "#!/bin/sh\n# SUMMARY: From PATH\n")
+	t.Setenv("PATH", dir)
+
+	entrypoint, err := New([]string{fixtures}, WithName("exoskeleton.test"), SearchPATH(), AppendCommands(&EmbeddedCommand{Name: "greet"}))
+	assert.NoError(t, err)
+
+	// Commands in the paths given to New take precedence over commands on $PATH...
+	assert.Equal(t, filepath.Join(fixtures, "hello"), entrypoint.cmds.Find("hello").Path())
+
+	// ...which take precedence over appended commands
+	greet := entrypoint.cmds.Find("greet")
+	assert.Equal(t, filepath.Join(dir, "exoskeleton.test-greet"), greet.Path())
+
+	cmds, err := namesakes(greet)
+	assert.NoError(t, err)
+	assert.Len(t, cmds, 2)
+	assert.True(t, IsEmbedded(cmds[1]))
+}
+
+func writeScript(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestModulesReturnDiscoveryErrorsWithSubcommands(t *testing.T) {
+	dir := t.TempDir()
+	module := filepath.Join(dir, "tools")
+	assert.NoError(t, os.Mkdir(module, 0755))
+	writeScript(t, filepath.Join(module, ".exoskeleton"), "# SUMMARY: Some tools\n")
+	writeScript(t, filepath.Join(module, "lint"), "#!/bin/sh\n# SUMMARY: Lints\n")
+	assert.NoError(t, os.Symlink(filepath.Join(dir, "missing"), filepath.Join(module, "broken")))
+
+	var reported []error
+	entrypoint, err := New([]string{dir}, OnError(func(_ *Entrypoint, err error) { reported = append(reported, err) }))
+	assert.NoError(t, err)
+	assert.Empty(t, reported)
+
+	tools := entrypoint.cmds.Find("tools")
+	cmds, err := tools.Subcommands()
+	assert.Equal(t, "lint", namesOf(cmds))
+
+	var partial PartialDiscoveryError
+	if assert.ErrorAs(t, err, &partial) {
+		assert.Equal(t, module, partial.Path)
+		assert.Len(t, partial.Errors, 1)
+	}
+	var symlinkErr SymlinkError
+	assert.ErrorAs(t, err, &symlinkErr)
+
+	// Errors are reported once, when they occur
+	assert.Len(t, reported, 1)
+	assert.ErrorAs(t, reported[0], &symlinkErr)
+
+	all, errs := entrypoint.cmds.Flatten()
+	assert.Contains(t, namesOf(all), "lint")
+	assert.Len(t, errs, 1)
+	assert.ErrorAs(t, errs[0], &partial)
+
+	menu, errs := MenuFor(tools, &MenuOptions{})
+	assert.Contains(t, menu, "lint  Lints")
+	assert.Len(t, errs, 1)
+	assert.ErrorAs(t, errs[0], &partial)
+
+	help, err := entrypoint.helpFor(tools, nil)
+	assert.NoError(t, err)
+	assert.Contains(t, help, "lint  Lints")
+	assert.Len(t, reported, 1)
+
+	cmd, _, err := entrypoint.Identify([]string{"tools", "lint"})
+	assert.NoError(t, err)
+	assert.Equal(t, "lint", cmd.Name())
+}
+
+func TestWithConcurrencyBoundsChildProcesses(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a", "b", "c", "d", "e", "f"} {
+		writeScript(t, filepath.Join(dir, name+".exoskeleton"), `#!/bin/sh
+sleep 0.1
+echo '{"name": "`+name+`", "summary": "`+name+`", "commands": [{"name": "sub", "summary": "Sub"}]}'
+`)
+	}
+
+	var running, max int32
+	executor := func(cmd *exec.Cmd) error {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			if m := atomic.LoadInt32(&max); n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+				break
+			}
+		}
+		return cmd.Run()
+	}
+
+	entrypoint, err := New([]string{dir}, WithConcurrency(2), WithExecutor(executor))
+	assert.NoError(t, err)
+
+	all, errs := entrypoint.cmds.Flatten()
+	assert.Empty(t, errs)
+	assert.Equal(t, "help\nwhich\ncomplete\na\nsub\nb\nsub\nc\nsub\nd\nsub\ne\nsub\nf\nsub", namesOf(all))
+	assert.LessOrEqual(t, atomic.LoadInt32(&max), int32(2))
+}
+
+func TestWithConcurrencyDoesNotBoundExecutedCommands(t *testing.T) {
+	dir := t.TempDir()
+	writeScript(t, filepath.Join(dir, "hello"), "#!/bin/sh\n# SUMMARY: Says hello\necho hello\n")
+
+	var stdout bytes.Buffer
+	entrypoint, err := New([]string{dir}, WithConcurrency(1), WithStdout(&stdout))
+	assert.NoError(t, err)
+
+	// Occupy the only slot for commands run to obtain metadata
+	release, err := acquireMetadataSlot(context.Background(), entrypoint)
+	assert.NoError(t, err)
+	defer release()
+
+	assert.NoError(t, entrypoint.cmds.Find("hello").Exec(entrypoint, nil, nil))
+	assert.Equal(t, "hello\n", stdout.String())
+}
@@ -0,0 +1,351 @@
+package exoskeleton
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/square/exit"
+)
+
+// DoctorHelp is the help text for the built-in 'doctor' command.
+const DoctorHelp = `USAGE
+   %[1]s doctor [--json] [--slow <duration>]
+
+   Discovers every command and exercises the ways %[1]s asks them for
+   metadata (their summary, help, subcommands, and completions) to find
+   commands that don't fulfill their contract, respond slowly, or share a
+   name with another command. Exits unsuccessfully if problems are found.
+
+   Commands are executed with --complete as though <TAB> were pressed.
+
+OPTIONS
+   --json              Print findings as JSON
+   --slow <duration>   Report commands that take longer than this to respond (default: 1s)
+
+EXAMPLES
+   %[1]s doctor
+   %[1]s doctor --json --slow 250ms`
+
+// defaultSlowThreshold is how long a command may take to respond before the
+// 'doctor' command reports it as slow.
+const defaultSlowThreshold = time.Second
+
+// doctorFinding is a problem (or a warning) found by the 'doctor' command.
+type doctorFinding struct {
+	// Severity is "error" or "warning". Only errors are problems.
+	Severity string `json:"severity"`
+
+	// Check is what was being checked: "discovery", "summary", "help",
+	// "describe", "help-opencli", "complete", "collision", or "slow".
+	Check string `json:"check"`
+
+	// Command is the usage of the command (if a command was built).
+	Command string `json:"command,omitempty"`
+
+	// Path is the path to the file that defines the command.
+	Path string `json:"path,omitempty"`
+
+	// ErrorType is the type of the error that was found (e.g. "CommandSummaryError").
+	ErrorType string `json:"errorType,omitempty"`
+
+	Message string `json:"message"`
+
+	// Duration is how long the command took to respond, in milliseconds.
+	Duration int64 `json:"durationMs,omitempty"`
+}
+
+type doctorReport struct {
+	Commands int             `json:"commands"`
+	Problems int             `json:"problems"`
+	Findings []doctorFinding `json:"findings"`
+}
+
+// doctor audits the commands that an Entrypoint discovers.
+type doctor struct {
+	e      *Entrypoint
+	slow   time.Duration
+	report doctorReport
+}
+
+// DoctorExec implements the 'doctor' command.
+func DoctorExec(e *Entrypoint, args, _ []string) error {
+	slow := defaultSlowThreshold
+	asJSON := false
+
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; {
+		case arg == "--json":
+			asJSON = true
+		case arg == "--slow" && i+1 < len(args):
+			i++
+			d, err := time.ParseDuration(args[i])
+			if err != nil {
+				return exit.Wrap(err, exit.UsageError)
+			}
+			slow = d
+		case strings.HasPrefix(arg, "--slow="):
+			d, err := time.ParseDuration(strings.TrimPrefix(arg, "--slow="))
+			if err != nil {
+				return exit.Wrap(err, exit.UsageError)
+			}
+			slow = d
+		default:
+			return exit.Wrap(fmt.Errorf("unexpected argument: %s", arg), exit.UsageError)
+		}
+	}
+
+	report := e.doctor(slow)
+
+	if asJSON {
+		b, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(e.Stdout(), string(b))
+	} else {
+		fmt.Fprint(e.Stdout(), report.String())
+	}
+
+	if report.Problems > 0 {
+		return exit.ErrNotOK
+	}
+	return nil
+}
+
+// doctor discovers the Entrypoint's commands again (without a cache, so that
+// every command is executed) and audits them.
+func (e *Entrypoint) doctor(slow time.Duration) doctorReport {
+	d := &doctor{e: e, slow: slow}
+	d.report.Findings = []doctorFinding{}
+
+	discovery := &DiscoveryReport{}
+	cmds := e.rediscover(nullCache{}, discovery)
+	cmds = append(append(append(Commands{}, e.cmdsToPrepend...), cmds...), e.cmdsToAppend...)
+	d.audit(cmds)
+
+	// Subcommands of modules were discovered while they were audited
+	for _, dir := range discovery.Directories {
+		d.auditDirectory(dir)
+	}
+
+	for _, finding := range d.report.Findings {
+		if finding.Severity == "error" {
+			d.report.Problems++
+		}
+	}
+	return d.report
+}
+
+func (d *doctor) audit(cmds Commands) {
+	d.auditCollisions(cmds)
+
+	for _, cmd := range cmds {
+		d.report.Commands++
+
+		// Stop at the first failure: the checks that follow tend to fail
+		// for the same reason (e.g. a module's subcommands can't be described).
+		var subcmds Commands
+		ok := d.checkOpenCLI(cmd) && d.check(cmd, "describe", "error", func() (err error) {
+			subcmds, err = cmd.Subcommands()
+			if isPartial(err) {
+				return nil // Reported with the module's directory
+			}
+			return err
+		}) && d.check(cmd, "summary", "error", func() error {
+			_, err := cmd.Summary()
+			return err
+		}) && d.check(cmd, "help", "error", func() error {
+			_, err := cmd.Help()
+			return err
+		})
+
+		if !ok {
+			continue
+		} else if len(subcmds) > 0 {
+			d.audit(subcmds)
+		} else if _, builtin := cmd.(*builtinCommand); !builtin {
+			// Commands MAY respond to --complete, so failing to is only a warning
+			d.check(cmd, "complete", "warning", func() error {
+				_, _, err := CompleteCommand(context.Background(), cmd, d.e, []string{""}, nil)
+				return err
+			})
+		}
+	}
+}
+
+// checkOpenCLI checks that an executable discovered by OpenCLIContract responds
+// to --help-opencli with an OpenCLI document. (Its subcommands are described by
+// the same document, so only the executable itself is checked.) It returns true
+// for other commands.
+func (d *doctor) checkOpenCLI(cmd Command) bool {
+	c, ok := cmd.(*executableCommand)
+	if !ok || c.contract != "OpenCLI" || len(c.args) > 0 {
+		return true
+	}
+	return d.check(cmd, "help-opencli", "error", func() error {
+		out, err := helpOpenCLIRaw(context.Background(), c)
+		if err != nil {
+			return err
+		}
+		_, err = parseOpenCLI(c, out)
+		return err
+	})
+}
+
+// check times fn and records a finding with the given severity if it fails (or
+// a warning if it is slow). It returns false if fn failed.
+func (d *doctor) check(cmd Command, check, severity string, fn func() error) bool {
+	start := time.Now()
+	err := fn()
+	duration := time.Since(start)
+
+	if err != nil {
+		d.add(doctorFinding{
+			Severity:  severity,
+			Check:     check,
+			Command:   Usage(cmd),
+			Path:      cmd.Path(),
+			ErrorType: errorType(err),
+			Message:   err.Error(),
+			Duration:  duration.Milliseconds(),
+		})
+	} else if d.slow > 0 && duration > d.slow {
+		d.add(doctorFinding{
+			Severity: "warning",
+			Check:    "slow",
+			Command:  Usage(cmd),
+			Path:     cmd.Path(),
+			Message:  fmt.Sprintf("took %s to respond to %s (more than %s)", duration.Round(time.Millisecond), check, d.slow),
+			Duration: duration.Milliseconds(),
+		})
+	}
+	return err == nil
+}
+
+// auditCollisions records a warning for each name (or alias) that is shared by
+// more than one of the given commands. (Only the first one can be run.)
+func (d *doctor) auditCollisions(cmds Commands) {
+	byName := map[string]Commands{}
+	var names []string
+	for _, cmd := range cmds {
+		for _, name := range append([]string{cmd.Name()}, cmd.Aliases()...) {
+			if _, ok := byName[name]; !ok {
+				names = append(names, name)
+			}
+			byName[name] = append(byName[name], cmd)
+		}
+	}
+
+	for _, name := range names {
+		if namesakes := byName[name]; len(namesakes) > 1 {
+			paths := make([]string, len(namesakes))
+			for i, cmd := range namesakes {
+				paths[i] = cmd.Path()
+			}
+			d.add(doctorFinding{
+				Severity: "warning",
+				Check:    "collision",
+				Command:  UsageRelativeTo(namesakes[0].Parent(), nil) + " " + name,
+				Path:     namesakes[0].Path(),
+				Message:  fmt.Sprintf("%d commands are named %s; only the first can be run: %s", len(namesakes), name, strings.Join(paths, ", ")),
+			})
+		}
+	}
+}
+
+// auditDirectory records the errors that occurred discovering commands in a
+// directory and the executables that no contract applied to because they
+// failed to respond to --summary.
+func (d *doctor) auditDirectory(dir *DirectoryReport) {
+	if dir.Err != nil {
+		d.add(doctorFinding{
+			Severity:  "error",
+			Check:     "discovery",
+			Path:      dir.Path,
+			ErrorType: errorType(dir.Err),
+			Message:   dir.Err.Error(),
+		})
+	}
+
+	for _, entry := range dir.Entries {
+		if entry.Err != nil {
+			d.add(doctorFinding{
+				Severity:  "error",
+				Check:     "discovery",
+				Path:      entry.Path,
+				ErrorType: errorType(entry.Err),
+				Message:   entry.Err.Error(),
+			})
+			continue
+		}
+
+		if entry.Contract != nil {
+			continue
+		}
+		for _, rejection := range entry.NotApplicable {
+			if errors.As(rejection.Reason, &CommandSummaryError{}) {
+				d.add(doctorFinding{
+					Severity:  "error",
+					Check:     "summary",
+					Path:      entry.Path,
+					ErrorType: errorType(rejection.Reason),
+					Message:   "ignored because no contract applies: " + rejection.Reason.Error(),
+				})
+			}
+		}
+	}
+}
+
+func (d *doctor) add(finding doctorFinding) {
+	d.report.Findings = append(d.report.Findings, finding)
+}
+
+// errorType returns the name of the most specific kind of error that err is or
+// wraps (e.g. "CommandSummaryError").
+func errorType(err error) string {
+	switch {
+	case errors.As(err, &CommandTimeoutError{}):
+		return "CommandTimeoutError"
+	case errors.As(err, &CommandSummaryError{}):
+		return "CommandSummaryError"
+	case errors.As(err, &CommandHelpError{}):
+		return "CommandHelpError"
+	case errors.As(err, &CommandDescribeError{}):
+		return "CommandDescribeError"
+	case errors.As(err, &CommandCompleteError{}):
+		return "CommandCompleteError"
+	case errors.As(err, &SymlinkError{}):
+		return "SymlinkError"
+	case errors.As(err, &DiscoveryError{}):
+		return "DiscoveryError"
+	case errors.As(err, new(*os.PathError)):
+		return "PathError"
+	default:
+		return strings.TrimPrefix(fmt.Sprintf("%T", err), "*")
+	}
+}
+
+// String renders the report for people to read.
+func (r doctorReport) String() string {
+	var b strings.Builder
+	for _, f := range r.Findings {
+		subject := f.Command
+		if subject == "" {
+			subject = f.Path
+		}
+		fmt.Fprintf(&b, "%s: %s: %s: ", f.Severity, f.Check, subject)
+		if f.ErrorType != "" {
+			fmt.Fprintf(&b, "%s: ", f.ErrorType)
+		}
+		fmt.Fprintln(&b, f.Message)
+	}
+
+	warnings := len(r.Findings) - r.Problems
+	fmt.Fprintf(&b, "\nChecked %d commands: %d problems, %d warnings\n", r.Commands, r.Problems, warnings)
+	return b.String()
+}
@@ -0,0 +1,143 @@
+package exoskeleton
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/square/exit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoctor(t *testing.T) {
+	a, b := t.TempDir(), t.TempDir()
+
+	// Fails to respond to --summary, so no contract applies
+	writeScript(t, filepath.Join(a, "fails"), "#!/bin/sh\nexit 1\n")
+
+	// Responds to --describe-commands with invalid JSON
+	writeScript(t, filepath.Join(a, "mod.exoskeleton"), `#!/bin/sh
+case "$1" in
+  --summary) echo "A module" ;;
+  --describe-commands) echo "{" ;;
+esac
+`)
+
+	// Responds slowly to --help
+	writeScript(t, filepath.Join(a, "slow"), `#!/bin/sh
+case "$1" in
+  --summary) echo "Responds slowly" ;;
+  --help) sleep 0.2; echo "USAGE" ;;
+esac
+`)
+
+	// Shares its name with a command in the first directory
+	writeScript(t, filepath.Join(b, "slow"), "#!/bin/sh\necho \"Shadowed\"\n")
+
+	require.NoError(t, os.Symlink(filepath.Join(a, "missing"), filepath.Join(a, "broken")))
+
+	entrypoint, err := New([]string{a, b}, WithName("e"), WithDoctor(), WithContracts(
+		&ExecutableContract{},
+		&StandaloneExecutableContract{},
+	))
+	require.NoError(t, err)
+
+	report := entrypoint.doctor(100 * time.Millisecond)
+
+	findings := map[string]doctorFinding{}
+	for _, finding := range report.Findings {
+		findings[finding.Check+" "+filepath.Base(finding.Path)] = finding
+	}
+
+	if finding, ok := findings["summary fails"]; assert.True(t, ok, "should report the executable that failed --summary") {
+		assert.Equal(t, "error", finding.Severity)
+		assert.Equal(t, "CommandSummaryError", finding.ErrorType)
+	}
+
+	if finding, ok := findings["describe mod.exoskeleton"]; assert.True(t, ok, "should report the module's invalid JSON") {
+		assert.Equal(t, "error", finding.Severity)
+		assert.Equal(t, "e mod", finding.Command)
+		assert.Equal(t, "CommandDescribeError", finding.ErrorType)
+	}
+
+	if finding, ok := findings["discovery broken"]; assert.True(t, ok, "should report the broken symlink") {
+		assert.Equal(t, "error", finding.Severity)
+		assert.Equal(t, "SymlinkError", finding.ErrorType)
+	}
+
+	if finding, ok := findings["slow slow"]; assert.True(t, ok, "should report the slow command") {
+		assert.Equal(t, "warning", finding.Severity)
+		assert.Equal(t, "e slow", finding.Command)
+		assert.GreaterOrEqual(t, finding.Duration, int64(100))
+	}
+
+	if finding, ok := findings["collision slow"]; assert.True(t, ok, "should report the name collision") {
+		assert.Equal(t, "warning", finding.Severity)
+		assert.Contains(t, finding.Message, filepath.Join(b, "slow"))
+	}
+
+	if finding, ok := findings["complete slow"]; assert.True(t, ok, "should report that the command didn't respond to --complete") {
+		assert.Equal(t, "warning", finding.Severity, "commands aren't required to respond to --complete")
+		assert.Equal(t, "CommandCompleteError", finding.ErrorType)
+	}
+
+	assert.Equal(t, 3, report.Problems)
+	assert.Contains(t, report.String(), "Checked 7 commands: 3 problems, 4 warnings")
+
+	// JSON output is meant to be consumed by CI
+	out, err := json.Marshal(report)
+	require.NoError(t, err)
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(out, &decoded))
+	assert.Equal(t, float64(3), decoded["problems"])
+	assert.Len(t, decoded["findings"], 7)
+}
+
+func TestDoctorChecksHelpOpenCLI(t *testing.T) {
+	dir := t.TempDir()
+
+	// Responds to --help-opencli with invalid JSON
+	writeScript(t, filepath.Join(dir, "tool"), `#!/bin/sh
+case "$1" in
+  --help-opencli) echo "{" ;;
+esac
+`)
+
+	entrypoint, err := New([]string{dir}, WithName("e"), WithDoctor(), WithContracts(&OpenCLIContract{}))
+	require.NoError(t, err)
+
+	report := entrypoint.doctor(0)
+
+	require.Len(t, report.Findings, 1, "should stop at the first failure")
+	finding := report.Findings[0]
+	assert.Equal(t, "help-opencli", finding.Check)
+	assert.Equal(t, "error", finding.Severity)
+	assert.Equal(t, "e tool", finding.Command)
+	assert.Equal(t, "CommandDescribeError", finding.ErrorType)
+	assert.Equal(t, 1, report.Problems)
+}
+
+func TestDoctorExec(t *testing.T) {
+	dir := t.TempDir()
+	writeScript(t, filepath.Join(dir, "hello"), "#!/bin/sh\n# SUMMARY: Says hello\n")
+
+	entrypoint, err := New([]string{dir}, WithName("e"), WithDoctor())
+	require.NoError(t, err)
+
+	cmd, _, err := entrypoint.Identify([]string{"doctor"})
+	require.NoError(t, err)
+	assert.Equal(t, "e doctor", Usage(cmd))
+
+	assert.NoError(t, DoctorExec(entrypoint, []string{"--json"}, nil))
+
+	writeScript(t, filepath.Join(dir, "fails"), "#!/bin/sh\nexit 1\n")
+	entrypoint, err = New([]string{dir}, WithName("e"), WithDoctor(), WithContracts(&StandaloneExecutableContract{}))
+	require.NoError(t, err)
+	assert.ErrorIs(t, DoctorExec(entrypoint, nil, nil), exit.ErrNotOK)
+
+	err = DoctorExec(entrypoint, []string{"--slow", "soon"}, nil)
+	assert.Equal(t, exit.UsageError, exit.FromError(err))
+}
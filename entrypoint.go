@@ -1,12 +1,18 @@
 package exoskeleton
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"text/template"
+	"time"
 
+	"github.com/square/exit"
 	"github.com/square/exoskeleton/v2/pkg/shellcomp"
 )
 
@@ -17,6 +23,30 @@ type CommandNotFoundFunc func(*Entrypoint, Command)
 // the remaining arguments that were not used to identify the command.
 type AfterIdentifyFunc func(*Entrypoint, Command, []string)
 
+// BeforeExecFunc is a function that is called before a command is executed. It
+// accepts the Command and the arguments it is executed with.
+type BeforeExecFunc func(*Entrypoint, Command, []string)
+
+// AfterExecFunc is a function that is called after a command is executed. It
+// accepts the Command, the arguments it was executed with, and the ExecResult.
+type AfterExecFunc func(*Entrypoint, Command, []string, ExecResult)
+
+// ExecResult describes the execution of a command.
+type ExecResult struct {
+	// Start is when the command started.
+	Start time.Time
+
+	// Duration is how long the command ran.
+	Duration time.Duration
+
+	// Err is the error returned by the command's Exec method.
+	Err error
+
+	// ExitCode is the code the entrypoint exits with if it passes Err to
+	// exit.FromError (as exoskeleton.Exec does).
+	ExitCode int
+}
+
 // ErrorFunc is called when an error occurs.
 type ErrorFunc func(*Entrypoint, error)
 
@@ -30,25 +60,62 @@ type MenuHeadingForFunc func(parent Command, cmd Command) string
 
 type ExecutorFunc func(*exec.Cmd) error
 
-func defaultExecutor(cmd *exec.Cmd) error { return cmd.Run() }
+// defaultConcurrency is the default number of commands that may run at once.
+var defaultConcurrency = runtime.GOMAXPROCS(0) * 4
+
+// acquireMetadataSlot blocks until fewer commands are running to obtain metadata
+// than the limit of the Entrypoint at the root of cmd's tree (see WithConcurrency)
+// or until ctx is done. It returns a function that releases the slot.
+func acquireMetadataSlot(ctx context.Context, cmd Command) (release func(), err error) {
+	e := entrypointOf(cmd)
+	if e == nil || e.metadataSlots == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case e.metadataSlots <- struct{}{}:
+		return func() { <-e.metadataSlots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
 
 // Entrypoint is the root of an exoskeleton CLI application.
 type Entrypoint struct {
 	path                     string
+	paths                    []string
 	name                     string
 	cmds                     Commands
 	maxDepth                 int
 	menuHeadingFor           MenuHeadingForFunc
 	menuTemplate             *template.Template
+	color                    ColorMode
+	customTheme              *Theme
 	moduleMetadataFilename   string
 	errorCallbacks           []ErrorFunc
 	afterIdentifyCallbacks   []AfterIdentifyFunc
+	beforeExecCallbacks      []BeforeExecFunc
+	afterExecCallbacks       []AfterExecFunc
 	commandNotFoundCallbacks []CommandNotFoundFunc
 	executor                 ExecutorFunc
+	customExecutor           bool
+	concurrency              int
+	metadataSlots            chan struct{}
+	timeouts                 Timeouts
+	processGroup             bool
+	replaceProcess           bool
+	envPrefix                string
+	logger                   *slog.Logger
+	stdin                    io.Reader
+	stdout                   io.Writer
+	stderr                   io.Writer
 	cmdsToAppend             []Command
 	cmdsToPrepend            []Command
 	contracts                []Contract
 	cache                    Cache
+	fsSources                []fsSource
+	searchPATH               bool
+	includeDoctor            bool
 }
 
 func (e *Entrypoint) Parent() Command                { return nil }
@@ -62,7 +129,7 @@ func (e *Entrypoint) Subcommands() (Commands, error) { return e.cmds, nil }
 
 // New searches the given paths and constructs an Entrypoint with a list of commands
 // discovered in those paths. It also accepts options that can be used to customize
-// the behavior of the Entrypoint.
+// the behavior of the Entrypoint. (Use WithFS to search paths within an fs.FS.)
 func New(paths []string, options ...Option) (*Entrypoint, error) {
 	path, err := os.Executable()
 	if err != nil {
@@ -70,6 +137,7 @@ func New(paths []string, options ...Option) (*Entrypoint, error) {
 	}
 
 	self := newWithDefaults(path)
+	self.paths = paths
 
 	helpCmd := &EmbeddedCommand{
 		Name:     "help",
@@ -82,9 +150,10 @@ func New(paths []string, options ...Option) (*Entrypoint, error) {
 		Complete: CompleteCommands,
 	}
 	completeCmd := &EmbeddedCommand{
-		Name:     "complete",
-		Exec:     CompleteExec,
-		Complete: nil,
+		Name:        "complete",
+		Exec:        CompleteExec,
+		ExecContext: CompleteExecContext,
+		Complete:    nil,
 	}
 
 	options =
@@ -107,17 +176,38 @@ func New(paths []string, options ...Option) (*Entrypoint, error) {
 				&DirectoryContract{
 					MetadataFilename: self.moduleMetadataFilename,
 				},
+				&SidecarContract{},
 				&ExecutableContract{},
 				&ShellScriptContract{},
 				&StandaloneExecutableContract{},
 			}
 	}
 
+	// user-provided options may have supplied a logger or redirected standard error
+	if self.logger == nil {
+		self.logger = loggerFromEnv(self.Stderr())
+	}
+
+	// Bound the number of commands run at once to obtain metadata (e.g. with
+	// --summary while building menus, which asks every command for its summary
+	// in parallel)
+	if self.concurrency > 0 {
+		self.metadataSlots = make(chan struct{}, self.concurrency)
+	}
+
 	// user-provided options may have overridden Name()
 	helpCmd.Help = fmt.Sprintf(HelpHelp, self.Name())
 	whichCmd.Help = fmt.Sprintf(WhichHelp, self.Name())
 	completeCmd.Help = fmt.Sprintf(CompleteHelp, self.Name())
 
+	if self.includeDoctor {
+		self.cmdsToAppend = append(self.cmdsToAppend, buildCommands(self, []*EmbeddedCommand{{
+			Name: "doctor",
+			Help: fmt.Sprintf(DoctorHelp, self.Name()),
+			Exec: DoctorExec,
+		}})...)
+	}
+
 	self.cmds =
 		append(
 			self.cmdsToPrepend,
@@ -136,13 +226,62 @@ func newWithDefaults(path string) *Entrypoint {
 		name:                   filepath.Base(path),
 		maxDepth:               -1,
 		moduleMetadataFilename: ".exoskeleton",
+		envPrefix:              defaultEnvPrefix,
 		executor:               defaultExecutor,
+		concurrency:            defaultConcurrency,
 		cmdsToPrepend:          []Command{},
 		cmdsToAppend:           []Command{},
 		cache:                  nullCache{},
 	}
 }
 
+// entrypointOf returns the Entrypoint at the root of the given Command's tree
+// (or nil if there isn't one).
+func entrypointOf(cmd Command) *Entrypoint {
+	for cmd.Parent() != nil {
+		cmd = cmd.Parent()
+	}
+	e, _ := cmd.(*Entrypoint)
+	return e
+}
+
+// Stdin returns the reader that executed commands read standard input from
+// (see WithStdin).
+func (e *Entrypoint) Stdin() io.Reader {
+	if e == nil || e.stdin == nil {
+		return os.Stdin
+	}
+	return e.stdin
+}
+
+// Stdout returns the writer that built-in commands write output to and that
+// executed commands write standard output to (see WithStdout). Embedded commands
+// should write their output to it, too.
+func (e *Entrypoint) Stdout() io.Writer {
+	if e == nil || e.stdout == nil {
+		return os.Stdout
+	}
+	return e.stdout
+}
+
+// Stderr returns the writer that warnings and errors are written to and that
+// executed commands write standard error to (see WithStderr). Embedded commands
+// should write their errors to it, too.
+func (e *Entrypoint) Stderr() io.Writer {
+	if e == nil || e.stderr == nil {
+		return os.Stderr
+	}
+	return e.stderr
+}
+
+// hasCustomStdio returns true if standard input, output, or error were redirected
+// to something other than the process's own (see WithStdin).
+func (e *Entrypoint) hasCustomStdio() bool {
+	return e.Stdin() != io.Reader(os.Stdin) ||
+		e.Stdout() != io.Writer(os.Stdout) ||
+		e.Stderr() != io.Writer(os.Stderr)
+}
+
 func (e *Entrypoint) onError(err error) {
 	for _, callback := range e.errorCallbacks {
 		callback(e, err)
@@ -155,18 +294,72 @@ func (e *Entrypoint) afterIdentify(cmd Command, args []string) {
 	}
 }
 
+// execWithCallbacks invokes BeforeExec callbacks, calls exec to execute cmd, and
+// invokes AfterExec callbacks with the result.
+func (e *Entrypoint) execWithCallbacks(cmd Command, args []string, exec func() error) error {
+	if e == nil {
+		return exec()
+	}
+
+	for _, callback := range e.beforeExecCallbacks {
+		callback(e, cmd, args)
+	}
+
+	start := time.Now()
+	err := exec()
+	result := ExecResult{
+		Start:    start,
+		Duration: time.Since(start),
+		Err:      err,
+		ExitCode: exit.FromError(err),
+	}
+
+	for _, callback := range e.afterExecCallbacks {
+		callback(e, cmd, args, result)
+	}
+	return err
+}
+
 func (e *Entrypoint) commandNotFound(cmd Command) {
 	for _, callback := range e.commandNotFoundCallbacks {
 		callback(e, cmd)
 	}
 
 	usage := UsageRelativeTo(cmd, e)
-	fmt.Fprintf(os.Stderr, "%s: no such command %s\n", e.Name(), usage)
+	fmt.Fprintf(e.Stderr(), "%s: no such command %s\n", e.Name(), usage)
 
 	if suggestions := e.suggestionsFor(usage); len(suggestions) > 0 {
-		fmt.Fprintln(os.Stderr, "Did you mean?")
+		fmt.Fprintln(e.Stderr(), "Did you mean?")
 		for _, suggestion := range suggestions {
-			fmt.Fprintf(os.Stderr, "   %s\n", Usage(suggestion))
+			fmt.Fprintf(e.Stderr(), "   %s\n", Usage(suggestion))
+		}
+	}
+}
+
+// afterExecKey marks the context of a command executed by ExecCommand when
+// AfterExec callbacks will be invoked after it.
+type afterExecKey struct{}
+
+// canReplaceProcess returns true if a command may replace the entrypoint's
+// process when it is executed with ctx (see ReplaceProcess).
+func (e *Entrypoint) canReplaceProcess(ctx context.Context) bool {
+	return e != nil &&
+		e.replaceProcess &&
+		!e.customExecutor && // The executor expects to run the command
+		ctx.Value(afterExecKey{}) == nil && // Nothing runs after the command
+		!e.processGroup &&
+		!e.hasCustomStdio() && // The process would inherit the entrypoint's own
+		ctx.Done() == nil // The process couldn't be killed when ctx is done
+}
+
+func (e *Entrypoint) warnIfDeprecated(cmd Command) {
+	if d, ok := cmd.(DeprecationReporter); ok {
+		if message, deprecated := d.Deprecated(); !deprecated {
+			return
+		} else if message == "" {
+			fmt.Fprintf(e.Stderr(), "warning: %s is deprecated\n", Usage(cmd))
+		} else {
+			fmt.Fprintf(e.Stderr(), "warning: %s is deprecated: %s\n", Usage(cmd), message)
 		}
 	}
 }
@@ -0,0 +1,179 @@
+package exoskeleton
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/square/exit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecCallbacks(t *testing.T) {
+	dir := t.TempDir()
+	writeScript(t, filepath.Join(dir, "ok"), "#!/bin/sh\n# SUMMARY: Succeeds\n")
+	writeScript(t, filepath.Join(dir, "fail"), "#!/bin/sh\n# SUMMARY: Fails\nexit 3\n")
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "module"), 0755))
+	writeScript(t, filepath.Join(dir, "module", ".exoskeleton"), "")
+
+	type call struct {
+		hook   string
+		cmd    Command
+		args   []string
+		result ExecResult
+	}
+	var calls []call
+
+	embedded := &EmbeddedCommand{
+		Name: "embedded",
+		Exec: func(e *Entrypoint, args, env []string) error { return exit.ErrUsageError },
+	}
+	entrypoint, err := New([]string{dir},
+		WithName("e"),
+		AppendCommands(embedded),
+		BeforeExec(func(_ *Entrypoint, cmd Command, args []string) {
+			calls = append(calls, call{hook: "before", cmd: cmd, args: args})
+		}),
+		AfterExec(func(_ *Entrypoint, cmd Command, args []string, result ExecResult) {
+			calls = append(calls, call{hook: "after", cmd: cmd, args: args, result: result})
+		}))
+	require.NoError(t, err)
+
+	scenarios := []struct {
+		args     []string
+		exitCode int
+	}{
+		{[]string{"ok", "a"}, exit.OK},
+		{[]string{"fail"}, exit.NotOK},
+		{[]string{"module"}, exit.OK},
+		{[]string{"embedded"}, exit.UsageError},
+		{[]string{}, exit.OK},
+	}
+
+	for _, s := range scenarios {
+		calls = nil
+		start := time.Now()
+
+		cmd, args, err := entrypoint.Identify(s.args)
+		require.NoError(t, err)
+		err = ExecCommand(context.Background(), cmd, entrypoint, args, nil)
+
+		if assert.Len(t, calls, 2, "args: %v", s.args) {
+			before, after := calls[0], calls[1]
+			assert.Equal(t, "before", before.hook)
+			assert.Same(t, cmd, before.cmd, "should be called with the identified command")
+			assert.Equal(t, args, before.args)
+
+			assert.Equal(t, "after", after.hook)
+			assert.Same(t, cmd, after.cmd, "should be called with the identified command")
+			assert.Equal(t, args, after.args)
+			assert.Equal(t, err, after.result.Err)
+			assert.Equal(t, s.exitCode, after.result.ExitCode, "args: %v", s.args)
+			assert.False(t, after.result.Start.Before(start))
+			assert.Greater(t, after.result.Duration, time.Duration(0))
+		}
+	}
+
+	// Commands built by other contracts get them too
+	calls = nil
+	cmd := &mockCommand{path: filepath.Join(dir, "custom")}
+	assert.NoError(t, ExecCommand(context.Background(), cmd, entrypoint, []string{"a"}, nil))
+	if assert.Len(t, calls, 2) {
+		assert.Same(t, cmd, calls[0].cmd)
+		assert.Same(t, cmd, calls[1].cmd)
+	}
+}
+
+func TestStdio(t *testing.T) {
+	dir := t.TempDir()
+	writeScript(t, filepath.Join(dir, "cat"), "#!/bin/sh\n# SUMMARY: Echoes its input\ncat\necho oops >&2\n")
+	writeScript(t, filepath.Join(dir, "old"), "#!/bin/sh\n# SUMMARY: Old\n# DEPRECATED: use cat instead\n")
+
+	var stdout, stderr bytes.Buffer
+	entrypoint, err := New([]string{dir},
+		WithName("e"),
+		WithStdin(strings.NewReader("input\n")),
+		WithStdout(&stdout),
+		WithStderr(&stderr),
+		ReplaceProcess())
+	require.NoError(t, err)
+
+	run := func(args ...string) error {
+		stdout.Reset()
+		stderr.Reset()
+		cmd, rest, err := entrypoint.Identify(args)
+		require.NoError(t, err)
+		return cmd.Exec(entrypoint, rest, nil)
+	}
+
+	assert.False(t, entrypoint.canReplaceProcess(context.Background()), "should not replace the process when stdio is redirected")
+
+	assert.NoError(t, run("cat"))
+	assert.Equal(t, "input\n", stdout.String())
+	assert.Equal(t, "oops\n", stderr.String())
+
+	assert.NoError(t, run())
+	assert.Contains(t, stdout.String(), "Echoes its input")
+
+	assert.NoError(t, run("help"))
+	assert.Contains(t, stdout.String(), "Echoes its input")
+
+	assert.NoError(t, run("which", "cat"))
+	assert.Equal(t, filepath.Join(dir, "cat")+"\n", stdout.String())
+
+	assert.NoError(t, run("complete", "ca"))
+	assert.Contains(t, stdout.String(), "cat\n")
+	assert.Contains(t, stderr.String(), "Completion ended with directive")
+
+	assert.ErrorIs(t, run("dog"), exit.ErrUnknownSubcommand)
+	assert.Empty(t, stdout.String())
+	assert.Contains(t, stderr.String(), "e: no such command dog")
+
+	assert.NoError(t, run("old"))
+	assert.Equal(t, "warning: e old is deprecated: use cat instead\n", stderr.String())
+}
+
+func TestStdinThatNeverEnds(t *testing.T) {
+	dir := t.TempDir()
+	writeScript(t, filepath.Join(dir, "hello"), "#!/bin/sh\necho hello\n")
+
+	stdin, w := io.Pipe()
+	defer w.Close()
+
+	var stdout bytes.Buffer
+	entrypoint, err := New([]string{dir}, WithName("e"), WithStdin(stdin), WithStdout(&stdout))
+	require.NoError(t, err)
+
+	done := make(chan error)
+	go func() { done <- entrypoint.cmds.Find("hello").Exec(entrypoint, nil, nil) }()
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+		assert.Equal(t, "hello\n", stdout.String())
+	case <-time.After(5 * time.Second):
+		t.Fatal("should return when the command exits, without waiting for the end of its input")
+	}
+}
+
+func TestCompletionErrorsWithAnUnwritableDebugFile(t *testing.T) {
+	if _, err := os.Stat("/dev/full"); err != nil {
+		t.Skip("/dev/full is not available")
+	}
+	t.Setenv("BASH_COMP_DEBUG_FILE", "/dev/full")
+
+	var stderr bytes.Buffer
+	entrypoint, err := New(nil, WithName("e"), WithStderr(&stderr))
+	require.NoError(t, err)
+
+	err = completionError(entrypoint, "oops")
+	assert.Equal(t, exit.NotOK, exit.FromError(err))
+	assert.Contains(t, stderr.String(), "Error: ")
+	assert.Contains(t, stderr.String(), "[Error] oops\n")
+}
@@ -0,0 +1,41 @@
+package exoskeleton
+
+import (
+	"os"
+	"strconv"
+)
+
+// defaultEnvPrefix prefixes the names of the variables that describe a command
+// to the process that executes it.
+const defaultEnvPrefix = "EXOSKELETON"
+
+// commandEnv returns env with variables that describe cmd and the Entrypoint
+// so that it can print accurate usage and invoke other commands:
+//
+//	EXOSKELETON_ENTRYPOINT_PATH         the path to the entrypoint executable
+//	EXOSKELETON_ENTRYPOINT_NAME         the name of the entrypoint (e.g. 'go')
+//	EXOSKELETON_COMMAND_USAGE           the command's usage (e.g. 'go mod tidy')
+//	EXOSKELETON_COMMAND_DISCOVERED_IN   the directory the command was discovered in
+//	EXOSKELETON_COMMAND_CONTRACT        the contract that built the command
+//	EXOSKELETON_COMMAND_DEPTH           how deeply the command is nested (0 for 'go test', 1 for 'go mod tidy')
+//
+// The prefix can be changed with WithEnvPrefix. If env is nil, the variables
+// are added to the current process's environment.
+func (e *Entrypoint) commandEnv(cmd *executableCommand, env []string) []string {
+	if e == nil || e.envPrefix == "" {
+		return env
+	}
+	if env == nil {
+		env = os.Environ()
+	}
+
+	prefix := e.envPrefix + "_"
+	return append(append([]string{}, env...),
+		prefix+"ENTRYPOINT_PATH="+e.Path(),
+		prefix+"ENTRYPOINT_NAME="+e.Name(),
+		prefix+"COMMAND_USAGE="+Usage(cmd),
+		prefix+"COMMAND_DISCOVERED_IN="+cmd.discoveredIn,
+		prefix+"COMMAND_CONTRACT="+cmd.contract,
+		prefix+"COMMAND_DEPTH="+strconv.Itoa(len(argsRelativeTo(cmd, e))-1),
+	)
+}
@@ -0,0 +1,70 @@
+package exoskeleton
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandEnv(t *testing.T) {
+	dir := t.TempDir()
+	module := filepath.Join(dir, "db")
+	require.NoError(t, os.Mkdir(module, 0755))
+	writeScript(t, filepath.Join(module, ".exoskeleton"), "")
+	writeScript(t, filepath.Join(module, "migrate"), "#!/bin/sh\n# SUMMARY: Migrates\nenv > \"$1\"\n")
+
+	readEnv := func(t *testing.T, e *Entrypoint) map[string]string {
+		out := filepath.Join(t.TempDir(), "env")
+		cmd, args, err := e.Identify([]string{"db", "migrate", out})
+		require.NoError(t, err)
+		require.NoError(t, cmd.Exec(e, args, []string{"EXISTING=1"}))
+
+		b, err := os.ReadFile(out)
+		require.NoError(t, err)
+		env := map[string]string{}
+		for _, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+			if name, value, ok := strings.Cut(line, "="); ok {
+				env[name] = value
+			}
+		}
+		return env
+	}
+
+	t.Run("default prefix", func(t *testing.T) {
+		entrypoint, err := New([]string{dir}, WithName("e"))
+		require.NoError(t, err)
+
+		env := readEnv(t, entrypoint)
+		assert.Equal(t, "1", env["EXISTING"])
+		assert.Equal(t, entrypoint.Path(), env["EXOSKELETON_ENTRYPOINT_PATH"])
+		assert.Equal(t, "e", env["EXOSKELETON_ENTRYPOINT_NAME"])
+		assert.Equal(t, "e db migrate", env["EXOSKELETON_COMMAND_USAGE"])
+		assert.Equal(t, module, env["EXOSKELETON_COMMAND_DISCOVERED_IN"])
+		assert.Equal(t, "ShellScript", env["EXOSKELETON_COMMAND_CONTRACT"])
+		assert.Equal(t, "1", env["EXOSKELETON_COMMAND_DEPTH"])
+	})
+
+	t.Run("custom prefix", func(t *testing.T) {
+		entrypoint, err := New([]string{dir}, WithName("e"), WithEnvPrefix("MYAPP"))
+		require.NoError(t, err)
+
+		env := readEnv(t, entrypoint)
+		assert.Equal(t, "e db migrate", env["MYAPP_COMMAND_USAGE"])
+		assert.NotContains(t, env, "EXOSKELETON_COMMAND_USAGE")
+	})
+
+	t.Run("no prefix", func(t *testing.T) {
+		entrypoint, err := New([]string{dir}, WithName("e"), WithEnvPrefix(""))
+		require.NoError(t, err)
+
+		env := readEnv(t, entrypoint)
+		assert.Equal(t, "1", env["EXISTING"])
+		for name := range env {
+			assert.False(t, strings.HasPrefix(name, "EXOSKELETON_"), "should not set %s", name)
+		}
+	})
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"os"
 
 	"github.com/square/exit"
@@ -24,8 +25,8 @@ func main() {
 		panic(err)
 	}
 
-	// Execute the subcommand.
-	err = cmd.Exec(cli, args, os.Environ())
+	// Execute the subcommand (invoking any BeforeExec and AfterExec callbacks).
+	err = exoskeleton.ExecCommand(context.Background(), cmd, cli, args, os.Environ())
 
 	// Exit the program with the exit code the subcommand returned.
 	os.Exit(exit.FromError(err))
@@ -1,6 +1,8 @@
 package exoskeleton
 
 import (
+	"context"
+	"errors"
 	"os"
 
 	"github.com/square/exit"
@@ -8,6 +10,13 @@ import (
 
 // Exec constructs an Entrypoint with the given paths and options and executes it.
 func Exec(paths []string, options ...Option) {
+	ExecContext(context.Background(), paths, options...)
+}
+
+// ExecContext is like Exec but kills the subcommand (and any commands executed
+// to identify it) when ctx is done. If ctx is done before the subcommand is
+// identified, it exits unsuccessfully.
+func ExecContext(ctx context.Context, paths []string, options ...Option) {
 	// Create a new Commandline application that will look for subcommands in the given paths.
 	e, err := New(paths, options...)
 	if err != nil {
@@ -15,13 +24,15 @@ func Exec(paths []string, options ...Option) {
 	}
 
 	// Identify the subcommand being invoked from the arguments.
-	cmd, args, err := e.Identify(os.Args[1:])
-	if err != nil {
+	cmd, args, err := e.IdentifyContext(ctx, os.Args[1:])
+	if err != nil && errors.Is(err, ctx.Err()) {
+		os.Exit(exit.FromError(err))
+	} else if err != nil {
 		panic(err)
 	}
 
 	// Execute the subcommand.
-	err = cmd.Exec(e, args, os.Environ())
+	err = ExecCommand(ctx, cmd, e, args, os.Environ())
 
 	// Exit the program with the exit code the subcommand returned.
 	os.Exit(exit.FromError(err))
@@ -0,0 +1,40 @@
+package exoskeleton
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestExecContextHelper is executed by TestExecContextExitsWhenCanceled in a
+// child process, which ExecContext exits.
+func TestExecContextHelper(t *testing.T) {
+	if os.Getenv("EXOSKELETON_TEST_EXEC_CONTEXT") == "" {
+		t.Skip("run by TestExecContextExitsWhenCanceled")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	ExecContext(ctx, nil, WithName("e"))
+	t.Fatal("should have exited")
+}
+
+func TestExecContextExitsWhenCanceled(t *testing.T) {
+	var stderr strings.Builder
+	c := exec.Command(os.Args[0], "-test.run=^TestExecContextHelper$")
+	c.Env = append(os.Environ(), "EXOSKELETON_TEST_EXEC_CONTEXT=1")
+	c.Stderr = &stderr
+	err := c.Run()
+
+	var exitErr *exec.ExitError
+	if assert.True(t, errors.As(err, &exitErr)) {
+		assert.Equal(t, 1, exitErr.ExitCode())
+	}
+	assert.NotContains(t, stderr.String(), "panic")
+}
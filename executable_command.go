@@ -2,9 +2,14 @@ package exoskeleton
 
 import (
 	"bytes"
+	"context"
 	"errors"
+	"io"
+	"io/fs"
 	"os"
 	"os/exec"
+	"sync"
+	"time"
 
 	"github.com/block/opencli-go"
 	"github.com/square/exoskeleton/v2/pkg/shellcomp"
@@ -12,7 +17,7 @@ import (
 
 // describeFunc fetches and parses the command descriptor for an executable module.
 // It is called by discover() to obtain the command tree.
-type describeFunc func(cmd *executableCommand) (*commandDescriptor, error)
+type describeFunc func(ctx context.Context, cmd *executableCommand) (*commandDescriptor, error)
 
 // executableCommand implements the Command interface for a file that can be executed.
 type executableCommand struct {
@@ -31,8 +36,38 @@ type executableCommand struct {
 	describe          describeFunc
 	contract          string
 	openCLI           *opencli.Command
+	fsys              fs.FS
+	interpreter       []string
+
+	// discoverMu guards discovery, which populates cmds, summary,
+	// defaultSubcommand and openCLI, so that it happens at most once even
+	// when menus are built concurrently. Its error is shared by every caller
+	// (unless discovery was interrupted by the caller's context).
+	discoverMu  sync.Mutex
+	discovered  bool
+	discoverErr error
+
+	// extractMu guards extracting the executable from fsys (see extract) so
+	// that once it is extracted, it isn't read and hashed again.
+	extractMu sync.Mutex
+	extracted string
 }
 
+// renamer is implemented by Commands whose name can be changed after they are
+// built (e.g. to remove the prefix from commands discovered on $PATH).
+type renamer interface {
+	rename(name string)
+}
+
+// Commands built by the built-in contracts can all be renamed.
+var (
+	_ renamer = &executableCommand{}
+	_ renamer = &shellScriptCommand{}
+	_ renamer = &directoryCommand{}
+	_ renamer = &manifestCommand{}
+	_ renamer = &sidecarCommand{}
+)
+
 func (cmd *executableCommand) Parent() Command      { return cmd.parent }
 func (cmd *executableCommand) Path() string         { return cmd.path }
 func (cmd *executableCommand) Name() string         { return cmd.name }
@@ -40,36 +75,156 @@ func (cmd *executableCommand) Aliases() []string    { return cmd.aliases }
 func (cmd *executableCommand) DiscoveredIn() string { return cmd.discoveredIn }
 func (cmd *executableCommand) Contract() string     { return cmd.contract }
 
+func (cmd *executableCommand) rename(name string)  { cmd.name = name }
+func (cmd *executableCommand) arguments() []string { return cmd.args }
+
 // Command returns an exec.Cmd that will run the executable with the given arguments.
+//
+// Executables discovered in an fs.FS other than the OS are extracted to disk first.
+// Commands with an interpreter are run by passing the executable to the interpreter.
 func (cmd *executableCommand) Command(args ...string) *exec.Cmd {
-	return exec.Command(cmd.path, append(cmd.args, args...)...)
+	return cmd.commandContext(context.Background(), args...)
+}
+
+// commandContext is like Command but the exec.Cmd is killed when ctx is done.
+func (cmd *executableCommand) commandContext(ctx context.Context, args ...string) *exec.Cmd {
+	path := cmd.path
+
+	var err error
+	if !isOS(cmd.fsys) {
+		path, err = cmd.extractedPath()
+	}
+
+	args = append(append([]string{path}, cmd.args...), args...)
+	if len(cmd.interpreter) > 0 {
+		args = append(append([]string{}, cmd.interpreter...), args...)
+	}
+
+	c := exec.CommandContext(ctx, args[0], args[1:]...)
+	if err != nil {
+		c.Err = err
+	}
+	if ctx.Done() != nil {
+		c.WaitDelay = waitDelay
+	}
+	return c
+}
+
+// extractedPath returns the location on disk of an executable discovered in an
+// fs.FS other than the OS, extracting it the first time it is needed. Failures
+// (like a full disk) aren't remembered, so extracting is tried again next time.
+func (cmd *executableCommand) extractedPath() (string, error) {
+	cmd.extractMu.Lock()
+	defer cmd.extractMu.Unlock()
+
+	if cmd.extracted == "" {
+		path, err := extract(cmd.fsys, cmd.path)
+		if err != nil {
+			return "", err
+		}
+		cmd.extracted = path
+	}
+	return cmd.extracted, nil
+}
+
+// open opens the file that defines the command for reading.
+func (cmd *executableCommand) open() (fs.File, error) {
+	if cmd.fsys == nil {
+		return os.Open(cmd.path)
+	}
+	return cmd.fsys.Open(cmd.path)
 }
 
 // Exec invokes the executable with the given arguments and environment.
 // If this command has subcommands, it prints the module help instead.
+// (With the ReplaceProcess option, the executable replaces the entrypoint.)
 func (cmd *executableCommand) Exec(e *Entrypoint, args, env []string) error {
-	if cmds, err := cmd.Subcommands(); err != nil {
+	return cmd.ExecContext(context.Background(), e, args, env)
+}
+
+// ExecContext is like Exec but the executable is killed if ctx is done
+// before it exits.
+//
+// If the executable is terminated by a signal, the error's exit code (see
+// exit.FromError) is 128 plus the signal's number.
+func (cmd *executableCommand) ExecContext(ctx context.Context, e *Entrypoint, args, env []string) error {
+	if cmds, err := cmd.subcommandsContext(ctx); err != nil {
 		return err
 	} else if len(cmds) > 0 {
 		return e.printModuleHelp(cmd, args)
 	}
-	command := cmd.Command(args...)
-	command.Stdin = os.Stdin
-	command.Stdout = os.Stdout
-	command.Stderr = os.Stderr
-	command.Env = env
-	return cmd.run(command)
+	command := cmd.commandContext(ctx, args...)
+	command.Stdin = e.Stdin()
+	command.Stdout = e.Stdout()
+	command.Stderr = e.Stderr()
+	command.Env = e.commandEnv(cmd, env)
+	if e != nil && e.processGroup {
+		setProcessGroup(command)
+	}
+	if e.canReplaceProcess(ctx) {
+		loggerFor(e).Debug("replacing process", "args", command.Args)
+
+		// replaceProcess only returns if the process can't be replaced
+		if err := replaceProcess(command); !errors.Is(err, errors.ErrUnsupported) {
+			return err
+		}
+	}
+	if _, ok := command.Stdin.(*os.File); !ok && command.Stdin != nil {
+		stdin, done, err := pipeStdin(command.Stdin)
+		if err != nil {
+			return err
+		}
+		defer done()
+		command.Stdin = stdin
+	}
+	return signalExitCode(cmd.execute(e, command))
+}
+
+// pipeStdin returns a pipe that a command can read r from, and a function to
+// call once it exits. (Given a reader that isn't a file, exec.Cmd's Wait doesn't
+// return until the reader is copied to EOF, which may be never; r is instead
+// copied by a goroutine that is left behind when the command exits.)
+func pipeStdin(r io.Reader) (*os.File, func(), error) {
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		return nil, nil, err
+	}
+	go func() {
+		_, _ = io.Copy(pw, r)
+		pw.Close()
+	}()
+	return pr, func() {
+		pr.Close()
+		pw.Close()
+	}, nil
 }
 
 // Complete invokes the executable with `--complete` as its first argument
 // and parses its output according to Cobra's ShellComp API.
-func (cmd *executableCommand) Complete(_ *Entrypoint, args, env []string) ([]string, shellcomp.Directive, error) {
-	if cmds, err := cmd.Subcommands(); err != nil {
+//
+// If the executable doesn't respond in time (see Timeouts), the error is reported
+// to the Entrypoint's OnError callbacks and there are no completions.
+func (cmd *executableCommand) Complete(e *Entrypoint, args, env []string) ([]string, shellcomp.Directive, error) {
+	return cmd.CompleteContext(context.Background(), e, args, env)
+}
+
+// CompleteContext is like Complete but the executable is killed if ctx is done
+// before it responds.
+func (cmd *executableCommand) CompleteContext(ctx context.Context, e *Entrypoint, args, env []string) ([]string, shellcomp.Directive, error) {
+	if cmds, err := cmd.subcommandsContext(ctx); err != nil {
 		return nil, shellcomp.DirectiveError, err
 	} else if len(cmds) > 0 {
 		return completionsForSubcommands(cmd, args)
 	}
-	return getCompletionsFromExecutable(cmd, args, env)
+
+	completions, directive, err := getCompletionsFromExecutable(ctx, cmd, args, e.commandEnv(cmd, env))
+	if errors.As(err, &CommandTimeoutError{}) {
+		if e != nil {
+			e.onError(err)
+		}
+		return []string{}, shellcomp.DirectiveNoFileComp, nil
+	}
+	return completions, directive, err
 }
 
 // Summary returns the (short!) description of the command to be displayed
@@ -82,17 +237,15 @@ func (cmd *executableCommand) Complete(_ *Entrypoint, args, env []string) ([]str
 // The executable is expected to write the summary to standard output and exit
 // successfully.
 func (cmd *executableCommand) Summary() (string, error) {
-	if cmd.discoverer != nil && cmd.cmds == nil {
-		if err := cmd.discover(); err != nil {
-			return "", err
-		}
+	if err := cmd.ensureDiscovered(context.Background()); err != nil {
+		return "", err
 	}
 
 	if cmd.summary != nil {
 		return *cmd.summary, nil
 	}
 
-	return cmd.cache.Fetch(cmd, "summary", func() (string, error) {
+	return fetch(context.Background(), cmd.cache, cmd, "summary", func(context.Context) (string, error) {
 		return readSummaryFromExecutable(cmd)
 	})
 }
@@ -122,19 +275,38 @@ func (cmd *executableCommand) DefaultSubcommand() Command {
 // Subcommands returns the list of subcommands for this command.
 // Returns an empty slice for leaf commands.
 func (cmd *executableCommand) Subcommands() (Commands, error) {
+	return cmd.subcommandsContext(context.Background())
+}
+
+func (cmd *executableCommand) subcommandsContext(ctx context.Context) (Commands, error) {
 	if cmd.discoverer == nil {
 		return Commands{}, nil // Leaf command
 	}
-	if cmd.cmds == nil {
-		if err := cmd.discover(); err != nil {
-			return nil, err
-		}
+	if err := cmd.ensureDiscovered(ctx); err != nil {
+		return nil, err
 	}
 	return cmd.cmds, nil
 }
 
 func (cmd *executableCommand) run(c *exec.Cmd) error {
-	return cmd.executor(c)
+	return cmd.runWith(cmd.executor, c)
+}
+
+// execute runs a command that is executed on behalf of the user. Unless an
+// executor was supplied with WithExecutor, signals that the entrypoint receives
+// are forwarded to it (see runForwardingSignals).
+func (cmd *executableCommand) execute(e *Entrypoint, c *exec.Cmd) error {
+	if e != nil && e.customExecutor {
+		return cmd.runWith(cmd.executor, c)
+	}
+	return cmd.runWith(runForwardingSignals, c)
+}
+
+func (cmd *executableCommand) runWith(executor ExecutorFunc, c *exec.Cmd) error {
+	start := time.Now()
+	err := executor(c)
+	loggerFor(cmd).Debug("executed command", "args", c.Args, "duration", time.Since(start), "error", err)
+	return err
 }
 
 func (cmd *executableCommand) output(c *exec.Cmd) ([]byte, error) {
@@ -156,18 +328,39 @@ func (cmd *executableCommand) output(c *exec.Cmd) ([]byte, error) {
 	return stdout.Bytes(), err
 }
 
+// ensureDiscovered discovers the command's subcommands once, unless they were
+// provided when the command was built (e.g. by its parent's descriptor).
+func (cmd *executableCommand) ensureDiscovered(ctx context.Context) error {
+	cmd.discoverMu.Lock()
+	defer cmd.discoverMu.Unlock()
+
+	if cmd.discovered {
+		return cmd.discoverErr
+	}
+
+	if cmd.discoverer != nil && cmd.cmds == nil {
+		if err := cmd.discover(ctx); err != nil && ctx.Err() != nil {
+			return err // Try again with the next caller's context
+		} else {
+			cmd.discoverErr = err
+		}
+	}
+	cmd.discovered = true
+	return cmd.discoverErr
+}
+
 // discover obtains a command descriptor and constructs a tree of modules and
 // subcommands (all to be invoked through the given executable) from it.
 //
 // When the describe field is set, it is used to obtain the descriptor.
 // Otherwise, the executable is invoked with --describe-commands.
-func (cmd *executableCommand) discover() error {
+func (cmd *executableCommand) discover(ctx context.Context) error {
 	describe := cmd.describe
 	if describe == nil {
 		describe = describeCommandsDefault
 	}
 
-	descriptor, err := describe(cmd)
+	descriptor, err := describe(ctx, cmd)
 	if err != nil {
 		return err
 	}
@@ -186,10 +379,8 @@ func (cmd *executableCommand) discover() error {
 // The returned Command describes only this node. Its Commands field is not
 // populated; walk Subcommands() to describe the command tree.
 func (cmd *executableCommand) OpenCLICommand() (*opencli.Command, error) {
-	if cmd.openCLI == nil && cmd.discoverer != nil && cmd.cmds == nil {
-		if err := cmd.discover(); err != nil {
-			return nil, err
-		}
+	if err := cmd.ensureDiscovered(context.Background()); err != nil {
+		return nil, err
 	}
 	if cmd.openCLI == nil {
 		return nil, nil
@@ -214,6 +405,7 @@ func toCommands(parent *executableCommand, descriptors []*commandDescriptor, arg
 			executor:          parent.executor,
 			cache:             parent.cache,
 			contract:          parent.contract,
+			fsys:              parent.fsys,
 		}
 
 		if len(descriptor.Commands) > 0 && d.MaxDepth() != 0 {
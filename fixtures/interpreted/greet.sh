@@ -0,0 +1,5 @@
+# SUMMARY: Greets someone
+# HELP: USAGE
+#    greet <name>
+
+echo "hello $1"
@@ -0,0 +1,5 @@
+// SUMMARY: Says hello
+// HELP: USAGE
+//    hello
+
+console.log('hello')
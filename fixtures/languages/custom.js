@@ -0,0 +1,4 @@
+#!/opt/runners/js
+// SUMMARY: Says hello from an unrecognized interpreter
+
+console.log("hello")
@@ -0,0 +1,155 @@
+package exoskeleton
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// osFS is the fs.FS in which commands on disk are discovered.
+//
+// Unlike os.DirFS, it is not rooted: it accepts the same native paths as the
+// functions in package os.
+type osFS struct{}
+
+func (osFS) Open(name string) (fs.File, error)          { return os.Open(name) }
+func (osFS) ReadDir(name string) ([]fs.DirEntry, error) { return os.ReadDir(name) }
+func (osFS) ReadFile(name string) ([]byte, error)       { return os.ReadFile(name) }
+func (osFS) Stat(name string) (fs.FileInfo, error)      { return os.Stat(name) }
+
+// isOS returns true if the given fs.FS refers to files on disk.
+func isOS(fsys fs.FS) bool {
+	_, ok := fsys.(osFS)
+	return fsys == nil || ok
+}
+
+// joinPath joins elements of a path in fsys: paths in an fs.FS are always
+// slash-separated, but paths on disk use the OS's separator.
+func joinPath(fsys fs.FS, elem ...string) string {
+	if isOS(fsys) {
+		return filepath.Join(elem...)
+	}
+	return path.Join(elem...)
+}
+
+// dirPath returns all but the last element of a path in fsys (see joinPath).
+func dirPath(fsys fs.FS, name string) string {
+	if isOS(fsys) {
+		return filepath.Dir(name)
+	}
+	return path.Dir(name)
+}
+
+// fsSource is a directory within an fs.FS to search for commands.
+type fsSource struct {
+	fsys fs.FS
+	path string
+}
+
+// fsEntry wraps the entries of file systems that don't record permissions (like
+// embed.FS, whose files are all read-only) so that their files that look like
+// executables (scripts and binaries) are reported as executable. Files with any
+// write bit and hidden files (like module metadata) keep their permissions.
+type fsEntry struct {
+	fs.DirEntry
+	fsys fs.FS
+	path string
+}
+
+func (e fsEntry) Info() (fs.FileInfo, error) {
+	info, err := e.DirEntry.Info()
+	if err != nil || info.IsDir() || info.Mode().Perm()&0222 != 0 || strings.HasPrefix(e.Name(), ".") {
+		return info, err
+	}
+	if !hasExecutableMagic(e.fsys, e.path) {
+		return info, nil
+	}
+	return executableFileInfo{info}, nil
+}
+
+// executableMagic are the bytes that begin scripts (a shebang), ELF and PE
+// binaries, and Mach-O binaries (32- and 64-bit in either byte order, and
+// universal binaries).
+var executableMagic = []string{
+	"#!",
+	"\x7fELF",
+	"MZ",
+	"\xfe\xed\xfa\xce", "\xce\xfa\xed\xfe",
+	"\xfe\xed\xfa\xcf", "\xcf\xfa\xed\xfe",
+	"\xca\xfe\xba\xbe",
+}
+
+// hasExecutableMagic returns true if the file at path in fsys begins like a
+// script or a binary.
+func hasExecutableMagic(fsys fs.FS, path string) bool {
+	f, err := fsys.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	b := make([]byte, 4)
+	n, _ := io.ReadFull(f, b)
+	for _, magic := range executableMagic {
+		if strings.HasPrefix(string(b[:n]), magic) {
+			return true
+		}
+	}
+	return false
+}
+
+type executableFileInfo struct{ fs.FileInfo }
+
+func (i executableFileInfo) Mode() fs.FileMode { return i.FileInfo.Mode() | 0555 }
+
+// extract copies the file at path in fsys to the user's cache directory so that
+// it can be executed and returns its location on disk. Each version of a file is
+// extracted only once.
+func extract(fsys fs.FS, path string) (string, error) {
+	b, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return "", err
+	}
+
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	sum := sha256.Sum256(b)
+	dir = filepath.Join(dir, "exoskeleton", "fs", hex.EncodeToString(sum[:8]))
+	dest := filepath.Join(dir, filepath.Base(path))
+
+	if _, err := os.Stat(dest); err == nil {
+		return dest, nil
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+
+	// Write to a temporary file and rename it so that concurrent extractions
+	// never execute a partially-written file.
+	f, err := os.CreateTemp(dir, ".extract-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := f.Chmod(0700); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+	return dest, os.Rename(f.Name(), dest)
+}
@@ -1,6 +1,7 @@
 package exoskeleton
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
 
@@ -30,13 +31,13 @@ func HelpExec(e *Entrypoint, args, _ []string) error {
 	} else if help, err := e.helpFor(cmd, rest); err != nil {
 		return err
 	} else {
-		printHelp(help)
+		e.printHelp(help)
 		return nil
 	}
 }
 
 func (e *Entrypoint) helpFor(cmd Command, args []string) (string, error) {
-	if subcmds, err := cmd.Subcommands(); err != nil {
+	if subcmds, err := cmd.Subcommands(); err != nil && !isPartial(err) {
 		return "", err
 	} else if len(subcmds) > 0 {
 		return e.buildModuleHelp(cmd, args)
@@ -47,19 +48,21 @@ func (e *Entrypoint) helpFor(cmd Command, args []string) (string, error) {
 
 func (e *Entrypoint) printModuleHelp(cmd Command, args []string) error {
 	help, err := e.buildModuleHelp(cmd, args)
-	printHelp(help)
+	e.printHelp(help)
 	return err
 }
 
 func (e *Entrypoint) buildModuleHelp(cmd Command, args []string) (string, error) {
 	// If `Subcommands()` will return an error, return early
-	if _, err := cmd.Subcommands(); err != nil {
+	if _, err := cmd.Subcommands(); err != nil && !isPartial(err) {
 		return "", err
 	}
 
+	theme := e.theme()
 	opts := &MenuOptions{
 		HeadingFor: e.menuHeadingFor,
 		Template:   e.menuTemplate,
+		Theme:      &theme,
 	}
 
 	for _, arg := range args {
@@ -72,17 +75,21 @@ func (e *Entrypoint) buildModuleHelp(cmd Command, args []string) (string, error)
 
 	menu, errs := MenuFor(cmd, opts)
 	for _, err := range errs {
-		e.onError(err)
+		// Discovery errors were reported when they occurred
+		if !isPartial(err) && !errors.As(err, &DiscoveryError{}) {
+			e.onError(err)
+		}
 	}
 	return menu, nil
 }
 
-func printHelp(help string) {
-	fmt.Println(formatHelp(help))
-	fmt.Println()
+func (e *Entrypoint) printHelp(help string) {
+	fmt.Fprintln(e.Stdout(), formatHelp(help, e.theme()))
+	fmt.Fprintln(e.Stdout())
 }
 
-func formatHelp(help string) string {
+// formatHelp styles the headings in help (lines like "USAGE" or "EXAMPLES").
+func formatHelp(help string, theme Theme) string {
 	re := regexp.MustCompile(`(?m)^([A-Z ]+)$`)
-	return string(re.ReplaceAll([]byte(help), []byte("\033[1m$1\033[0m")))
+	return re.ReplaceAllStringFunc(help, theme.Heading.Render)
 }
@@ -1,6 +1,9 @@
 package exoskeleton
 
-import "strings"
+import (
+	"context"
+	"strings"
+)
 
 // Identify identifies the command being invoked.
 //
@@ -13,24 +16,37 @@ import "strings"
 // returns NullCommand.
 //
 // Returns a CommandError if the command does not fulfill the contract
-// for providing its subcommands.
+// for providing its subcommands. (Commands can still be identified among
+// the subcommands of a module that returns a PartialDiscoveryError.)
 func (e *Entrypoint) Identify(args []string) (Command, []string, error) {
+	return e.IdentifyContext(context.Background(), args)
+}
+
+// IdentifyContext is like Identify but stops executing modules to discover
+// their subcommands when ctx is done. It returns ctx's error if it was done
+// before the command was identified.
+func (e *Entrypoint) IdentifyContext(ctx context.Context, args []string) (Command, []string, error) {
 	// Recognize `--complete` as an alias for the built-in `complete` command.
 	if len(args) > 0 && args[0] == "--complete" {
-		return e.Identify(append([]string{"complete"}, args[1:]...))
+		return e.IdentifyContext(ctx, append([]string{"complete"}, args[1:]...))
 	}
 
-	cmd, rest, err := identify(e, args)
+	cmd, rest, err := identify(ctx, e, args)
+	if ctx.Err() != nil {
+		return cmd, rest, ctx.Err()
+	}
+	loggerFor(e).Debug("identified command", "command", Usage(cmd), "args", rest, "error", err)
 
 	// Recognize `--help` and `-h` as aliases for the built-in `help` command
 	// only when they immediately follow an identifiable command.
 	if !IsNull(cmd) && len(rest) > 0 && (rest[0] == "--help" || rest[0] == "-h") {
-		return e.Identify(append(append([]string{"help"}, argsRelativeTo(cmd, e)...), rest[1:]...))
+		return e.IdentifyContext(ctx, append(append([]string{"help"}, argsRelativeTo(cmd, e)...), rest[1:]...))
 	}
 
 	if IsNull(cmd) {
 		e.commandNotFound(cmd)
 	} else if err == nil {
+		e.warnIfDeprecated(cmd)
 		e.afterIdentify(cmd, rest)
 	}
 
@@ -42,10 +58,14 @@ func (e *Entrypoint) Identify(args []string) (Command, []string, error) {
 //
 // Returns a CommandError if the command does not fulfill the contract
 // for providing its subcommands.
-func identify(cmd Command, args []string) (Command, []string, error) {
+func identify(ctx context.Context, cmd Command, args []string) (Command, []string, error) {
+	log := loggerFor(cmd)
+	log.Debug("identifying subcommand", "command", Usage(cmd), "args", args)
+
 	if len(args) == 0 || isFlag(args[0]) {
 		if len(args) > 0 {
 			if def := cmd.DefaultSubcommand(); def != nil {
+				log.Debug("using default subcommand", "command", Usage(def))
 				return def, args, nil
 			}
 		}
@@ -56,13 +76,14 @@ func identify(cmd Command, args []string) (Command, []string, error) {
 	// Do this just-in-time, non-destructively, while we're working on identifying a command.
 	name, rest := args[0], args[1:]
 	if strings.Contains(name, ":") {
-		return identify(cmd, append(without(strings.Split(name, ":"), ""), rest...))
+		return identify(ctx, cmd, append(without(strings.Split(name, ":"), ""), rest...))
 	}
 
-	if cmds, err := cmd.Subcommands(); err != nil {
+	if cmds, err := subcommandsContext(ctx, cmd); err != nil && !isPartial(err) {
 		return cmd, args, err
 	} else if found := cmds.Find(name); found == nil {
 		if def := cmd.DefaultSubcommand(); def != nil {
+			log.Debug("using default subcommand", "command", Usage(def))
 			return def, args, nil
 		}
 		return nullCommand{parent: cmd, name: name}, rest, nil
@@ -71,10 +92,10 @@ func identify(cmd Command, args []string) (Command, []string, error) {
 		// resolve found's subcommands. This lets callers like `which` identify
 		// a command even when it doesn't fulfill a discovery contract.
 		return found, rest, nil
-	} else if subcmds, err := found.Subcommands(); err != nil {
+	} else if subcmds, err := subcommandsContext(ctx, found); err != nil && !isPartial(err) {
 		return found, rest, err
 	} else if len(subcmds) > 0 {
-		return identify(found, rest)
+		return identify(ctx, found, rest)
 	} else {
 		return found, rest, nil
 	}
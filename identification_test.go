@@ -1,6 +1,7 @@
 package exoskeleton
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"testing"
@@ -55,7 +56,7 @@ func TestIdentifyDoesNotResolveSubcommandsWithoutRemainingArgs(t *testing.T) {
 		name:       "plain",
 		discoverer: &discoverer{},
 		cache:      nullCache{},
-		describe: func(cmd *executableCommand) (*commandDescriptor, error) {
+		describe: func(_ context.Context, cmd *executableCommand) (*commandDescriptor, error) {
 			described = true
 			return nil, errors.New("plain: error: unknown flag --help-opencli")
 		},
@@ -0,0 +1,33 @@
+// Package probe defines the interface pkg/exoskeletontest uses to execute an
+// executable the way an Entrypoint does to obtain its metadata (see
+// exoskeleton.NewContractProbe), without making its methods part of
+// Exoskeleton's public API.
+package probe
+
+import "github.com/square/exoskeleton/v2/pkg/shellcomp"
+
+// Probe executes an executable to obtain its metadata. Output is parsed exactly
+// as it is when the executable is discovered, and errors are the same kinds of
+// errors (e.g. exoskeleton.CommandSummaryError).
+type Probe interface {
+	// Summary executes the executable with --summary and returns its output
+	// (without trailing newlines).
+	Summary() (string, error)
+
+	// Help executes the executable with --help and returns its output.
+	Help() (string, error)
+
+	// Complete executes the executable with '--complete -- <args>' and parses
+	// its output with shellcomp.Unmarshal.
+	Complete(args ...string) ([]string, shellcomp.Directive, error)
+
+	// DescribeCommands executes the executable with --describe-commands (as
+	// ExecutableContract does for modules) and returns an error if its output
+	// can't be parsed.
+	DescribeCommands() error
+
+	// HelpOpenCLI executes the executable with --help-opencli (as
+	// OpenCLIContract does) and returns an error if its output isn't an
+	// OpenCLI document.
+	HelpOpenCLI() error
+}
@@ -0,0 +1,42 @@
+package exoskeleton
+
+import (
+	"io"
+	"log/slog"
+	"os"
+)
+
+// LogEnvVar is the environment variable that turns on logging to standard
+// error when no logger is supplied with WithLogger. Its value is the minimum
+// level of messages to log ("debug", "info", "warn", or "error"); any other
+// value logs everything.
+//
+//	$ EXOSKELETON_LOG=debug mycli deploy
+const LogEnvVar = "EXOSKELETON_LOG"
+
+// discardLogger is used when logging isn't turned on.
+var discardLogger = slog.New(slog.DiscardHandler)
+
+// loggerFromEnv returns a logger that writes to w (standard error) if LogEnvVar
+// is set or else a logger that discards messages.
+func loggerFromEnv(w io.Writer) *slog.Logger {
+	value, ok := os.LookupEnv(LogEnvVar)
+	if !ok || value == "" {
+		return discardLogger
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(value)); err != nil {
+		level = slog.LevelDebug
+	}
+	return slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: level}))
+}
+
+// loggerFor returns the logger of the Entrypoint at the root of the given
+// Command's tree (or a logger that discards messages if there isn't one).
+func loggerFor(cmd Command) *slog.Logger {
+	if e := entrypointOf(cmd); e != nil && e.logger != nil {
+		return e.logger
+	}
+	return discardLogger
+}
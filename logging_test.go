@@ -0,0 +1,89 @@
+package exoskeleton
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithLogger(t *testing.T) {
+	dir := t.TempDir()
+	writeScript(t, filepath.Join(dir, "hello"), "#!/bin/sh\necho hello\n")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README"), []byte("not a command"), 0644))
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	cache := &FileCache{Path: filepath.Join(t.TempDir(), "cache.json")}
+
+	entrypoint, err := New([]string{dir}, WithName("e"), WithLogger(logger), WithCache(cache))
+	require.NoError(t, err)
+
+	readme := filepath.Join(dir, "README")
+	assert.Contains(t, buf.String(), `msg="contract does not apply" path=`+readme+` contract=exoskeleton.ShellScriptContract reason="contract does not apply: not executable"`)
+	assert.Contains(t, buf.String(), `msg="no contract applies; ignoring file" path=`+readme)
+	assert.Contains(t, buf.String(), `msg="contract applies" path=`+filepath.Join(dir, "hello")+` contract=exoskeleton.ShellScriptContract commands=1`)
+
+	buf.Reset()
+	cmd, args, err := entrypoint.Identify([]string{"hello", "world"})
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), `msg="identifying subcommand" command=e args="[hello world]"`)
+	assert.Contains(t, buf.String(), `msg="identified command" command="e hello" args=[world]`)
+
+	buf.Reset()
+	require.NoError(t, cmd.Exec(entrypoint, args, nil))
+	assert.Contains(t, buf.String(), `msg="executed command" args="[`+filepath.Join(dir, "hello")+` world]" duration=`)
+
+	buf.Reset()
+	_, _ = cmd.Summary()
+	assert.Contains(t, buf.String(), `msg="cache miss" key=summary`)
+
+	buf.Reset()
+	_, _ = cmd.Summary()
+	assert.Contains(t, buf.String(), `msg="cache hit" key=summary`)
+}
+
+func TestLoggerFromEnv(t *testing.T) {
+	t.Setenv(LogEnvVar, "")
+	assert.Same(t, discardLogger, loggerFromEnv(os.Stderr))
+
+	t.Setenv(LogEnvVar, "info")
+	logger := loggerFromEnv(os.Stderr)
+	assert.False(t, logger.Enabled(context.Background(), slog.LevelDebug))
+	assert.True(t, logger.Enabled(context.Background(), slog.LevelInfo))
+
+	t.Setenv(LogEnvVar, "1")
+	assert.True(t, loggerFromEnv(os.Stderr).Enabled(context.Background(), slog.LevelDebug))
+}
+
+func TestFileCacheLogsCacheMissesOnce(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	cmd := &executableCommand{parent: &Entrypoint{logger: logger}, path: filepath.Join(t.TempDir(), "cmd")}
+	cache := &FileCache{Path: filepath.Join(t.TempDir(), "cache.json")}
+
+	// Callers that share a computation don't log that their value was cached
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = cache.Fetch(cmd, "summary", func() (string, error) {
+				time.Sleep(50 * time.Millisecond)
+				return "COMPUTED", nil
+			})
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, strings.Count(buf.String(), `msg="cache miss"`))
+	assert.NotContains(t, buf.String(), `msg="cache hit"`)
+}
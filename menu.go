@@ -2,24 +2,33 @@ package exoskeleton
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
 	"text/template"
 )
 
-const menuTemplate = "\033[1m" + `USAGE` + "\033[0m" + `
+const menuTemplate = `{{.Theme.Heading.Render "USAGE"}}
    {{.Usage}}
+{{- if .Description}}
+
+{{.Description}}
+{{- end}}
 
 {{- range .Sections}}
 
-` + "\033[1m" + `{{.Heading}}` + "\033[0m" + `
+{{$.Theme.Heading.Render .Heading}}
 {{- range .MenuItems}}
    {{rpad .Name .Width}}  {{.Summary}}
 {{- end}}
 {{- end}}
 
-Run ` + "\033[96m" + `{{.HelpUsage}} <command>` + "\033[0m" + ` to print information on a specific command.`
+{{.Theme.Hint.Render "Run"}} {{.Theme.Command.Render (printf "%s <command>" .HelpUsage)}} {{.Theme.Hint.Render "to print information on a specific command."}}`
+
+// unavailable is listed in menus in place of the summaries of commands that
+// don't respond in time.
+const unavailable = "(unavailable)"
 
 var templateFuncs = template.FuncMap{
 	"rpad": func(s string, padding int) string { return fmt.Sprintf("%*s", -padding, s) },
@@ -39,7 +48,8 @@ type MenuOptions struct {
 
 	// HeadingFor accepts the parent Command and a subcommand, returning a
 	// string to use as a section heading for the subcommand.
-	// The default function returns "COMMANDS".
+	// The default function returns "COMMANDS". Headings declared by
+	// commands (see HeadingReporter) take precedence.
 	HeadingFor MenuHeadingForFunc
 
 	// SummaryFor accepts a Command and returns its summary and, optionally, an error.
@@ -49,13 +59,18 @@ type MenuOptions struct {
 	// Template is executed with the constructed exoskeleton.Menu to render
 	// help content for a Command with subcommands.
 	Template *template.Template
+
+	// Theme styles the menu. (Default: DefaultTheme; use PlainTheme for no styles)
+	Theme *Theme
 }
 
 // Menu is the data passed to MenuOptions.Template when it is executed.
 type Menu struct {
-	Usage     string
-	HelpUsage string
-	Sections  MenuSections
+	Usage       string
+	Description string
+	HelpUsage   string
+	Sections    MenuSections
+	Theme       Theme
 }
 
 type MenuSections []MenuSection
@@ -87,6 +102,22 @@ type MenuItem struct {
 	Summary string
 	Heading string
 	Width   int
+
+	// rank orders items listed explicitly by their module before others
+	rank int
+}
+
+// subcommandOrderer is implemented by Commands that declare the order in which
+// their subcommands are listed in menus. Subcommands it doesn't name are listed
+// afterward, alphabetically.
+type subcommandOrderer interface {
+	subcommandOrder() []string
+}
+
+// moduleDescriber is implemented by Commands that have a description to show
+// above the menu of their subcommands.
+type moduleDescriber interface {
+	description() string
 }
 
 // MenuFor renders a menu of commands for a Command with subcommands.
@@ -113,12 +144,35 @@ func buildMenu(cmd Command, opts *MenuOptions) (*Menu, []error) {
 		opts.HeadingFor = func(Command, Command) string { return "COMMANDS" }
 	}
 
+	if opts.Theme == nil {
+		opts.Theme = &DefaultTheme
+	}
+
 	c, err := cmd.Subcommands()
-	if err != nil {
-		return &Menu{}, []error{err}
+	if err != nil && !isPartial(err) {
+		return &Menu{Theme: *opts.Theme}, []error{err}
 	}
 
 	c, errs := c.Expand(WithDepth(opts.Depth), WithoutExpandedModules())
+	if err != nil {
+		errs = append([]error{err}, errs...)
+	}
+
+	ranks := make(map[string]int)
+	if o, ok := cmd.(subcommandOrderer); ok {
+		for i, name := range o.subcommandOrder() {
+			ranks[name] = i + 1
+		}
+	}
+	rankOf := func(subcmd Command) int {
+		for subcmd.Parent() != nil && subcmd.Parent() != cmd {
+			subcmd = subcmd.Parent()
+		}
+		if rank, ok := ranks[subcmd.Name()]; ok {
+			return rank
+		}
+		return len(ranks) + 1
+	}
 
 	allItems, ferrs :=
 		parallelMap(c, func(subcmd Command) ([]*MenuItem, []error) {
@@ -127,8 +181,15 @@ func buildMenu(cmd Command, opts *MenuOptions) (*Menu, []error) {
 				name += ":"
 			}
 
+			if IsHidden(subcmd) {
+				return nil, nil
+			}
+
+			var errs []error
 			summary, err := opts.SummaryFor(subcmd)
-			if err != nil {
+			if errors.As(err, &CommandTimeoutError{}) {
+				summary, errs = unavailable, []error{err}
+			} else if err != nil {
 				return nil, []error{err}
 			}
 			if summary == "" {
@@ -136,7 +197,10 @@ func buildMenu(cmd Command, opts *MenuOptions) (*Menu, []error) {
 			}
 
 			heading := opts.HeadingFor(nil, subcmd)
-			return []*MenuItem{{Name: name, Summary: summary, Heading: heading}}, nil
+			if h, ok := subcmd.(HeadingReporter); ok && h.Heading() != "" {
+				heading = h.Heading()
+			}
+			return []*MenuItem{{Name: name, Summary: summary, Heading: heading, rank: rankOf(subcmd)}}, errs
 		})
 
 	errs = append(errs, ferrs...)
@@ -153,6 +217,9 @@ func buildMenu(cmd Command, opts *MenuOptions) (*Menu, []error) {
 
 	width := items.MaxWidth()
 
+	// List sections in the order of the first command listed in each
+	sort.SliceStable(items, func(i, j int) bool { return items[i].rank < items[j].rank })
+
 	byHeading := make(map[string]MenuItems)
 	var orderedHeadings []string
 	for _, menuItem := range items {
@@ -168,14 +235,22 @@ func buildMenu(cmd Command, opts *MenuOptions) (*Menu, []error) {
 		menuItems := byHeading[heading]
 		if len(menuItems) > 0 {
 			sort.Sort(menuItems)
+			sort.SliceStable(menuItems, func(i, j int) bool { return menuItems[i].rank < menuItems[j].rank })
 			sections = append(sections, MenuSection{heading, menuItems})
 		}
 	}
 
+	var description string
+	if d, ok := cmd.(moduleDescriber); ok {
+		description = d.description()
+	}
+
 	return &Menu{
-		Usage:     Usage(cmd) + " <command> [<args>]",
-		Sections:  sections,
-		HelpUsage: helpUsage(cmd),
+		Usage:       Usage(cmd) + " <command> [<args>]",
+		Description: description,
+		Sections:    sections,
+		HelpUsage:   helpUsage(cmd),
+		Theme:       *opts.Theme,
 	}, errs
 }
 
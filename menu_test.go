@@ -1,7 +1,6 @@
 package exoskeleton
 
 import (
-	"regexp"
 	"strings"
 	"testing"
 
@@ -25,10 +24,11 @@ func TestMenuForTrailer(t *testing.T) {
 	module := &directoryCommand{executableCommand: executableCommand{parent: entrypoint, name: "module", cache: nullCache{}}}
 	entrypoint.cmds = Commands{module}
 
-	menu, _ := MenuFor(entrypoint, &MenuOptions{})
-	assert.Contains(t, menu, "Run \033[96mentrypoint help <command>\033[0m to print information on a specific command.")
+	menu, _ := MenuFor(entrypoint, &MenuOptions{Theme: &PlainTheme})
+	assert.Contains(t, menu, "Run entrypoint help <command> to print information on a specific command.")
 
 	menu, _ = MenuFor(module, &MenuOptions{})
+	assert.Contains(t, menu, "\033[1mUSAGE\033[0m", "should use DefaultTheme unless told otherwise")
 	assert.Contains(t, menu, "Run \033[96mentrypoint help module <command>\033[0m to print information on a specific command.")
 }
 
@@ -94,18 +94,12 @@ func TestMenuForSections(t *testing.T) {
 	}
 
 	for _, s := range scenarios {
-		menu, errs := MenuFor(entrypoint, &MenuOptions{Depth: s.depth})
+		menu, errs := MenuFor(entrypoint, &MenuOptions{Depth: s.depth, Theme: &PlainTheme})
 		assert.Empty(t, errs)
-		assert.Equal(t, s.expected, sections(nocolor(menu)), "Given depth=%d", s.depth)
+		assert.Equal(t, s.expected, sections(menu), "Given depth=%d", s.depth)
 	}
 }
 
-// TODO: support NOCOLOR and remove this
-func nocolor(s string) string {
-	re := regexp.MustCompile("\033\\[[;\\d]+m")
-	return re.ReplaceAllLiteralString(s, "")
-}
-
 func sections(s string) string {
 	lines := strings.SplitAfter(s, "\n")
 	return strings.TrimRight(strings.Join(lines[3:(len(lines)-1)], ""), "\n")
@@ -0,0 +1,156 @@
+package exoskeleton
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// moduleMetadata is the content of a module's metadata file (e.g. '.exoskeleton').
+//
+// The file may be empty, contain magic comments (like '# SUMMARY:'), or declare
+// these fields as JSON or as YAML-like 'key: value' lines:
+//
+//	summary: Database tools
+//	description: |
+//	  Tools for managing the database.
+//	  Run migrations before deploying!
+//	aliases: [database]
+//	defaultCommand: status
+//	hidden: [repair]
+//	order: [status, migrate]
+type moduleMetadata struct {
+	Summary        *string  `json:"summary,omitempty"`
+	Description    string   `json:"description,omitempty"`
+	Aliases        []string `json:"aliases,omitempty"`
+	DefaultCommand string   `json:"defaultCommand,omitempty"`
+	Hidden         []string `json:"hidden,omitempty"`
+	Order          []string `json:"order,omitempty"`
+}
+
+func parseModuleMetadata(b []byte) (*moduleMetadata, error) {
+	var metadata moduleMetadata
+
+	if trimmed := bytes.TrimSpace(b); len(trimmed) > 0 && trimmed[0] == '{' {
+		if err := json.Unmarshal(trimmed, &metadata); err != nil {
+			return nil, fmt.Errorf("error parsing module metadata: %w", err)
+		}
+		return &metadata, nil
+	}
+
+	var magicSummary *string
+	var key string   // the key of a multi-line value (a block or a list)
+	var isBlock bool // true if the value is a block ('key: |') rather than a list
+	var block []string
+
+	flush := func() {
+		if key == "description" && isBlock {
+			metadata.Description = dedent(block)
+		}
+		key, isBlock, block = "", false, nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \r")
+
+		// Continue a multi-line value until a line isn't indented. Lines of a
+		// block are kept as they are (even if they look like list items).
+		if key != "" && (line == "" || strings.HasPrefix(line, " ")) {
+			if isBlock {
+				block = append(block, line)
+			} else if item, ok := strings.CutPrefix(strings.TrimSpace(line), "- "); ok {
+				metadata.set(key, item, true)
+			}
+			continue
+		}
+		flush()
+
+		if summary, ok := strings.CutPrefix(line, defaultCommentLeader+" SUMMARY:"); ok {
+			summary = strings.TrimPrefix(summary, " ")
+			magicSummary = &summary
+			continue
+		}
+		if line == "" || strings.HasPrefix(line, defaultCommentLeader) {
+			continue
+		}
+
+		k, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value, _, _ = strings.Cut(value, " #")
+		if value = strings.TrimSpace(value); value == "" || value == "|" {
+			key, isBlock = k, value == "|"
+		} else {
+			metadata.set(k, value, false)
+		}
+	}
+	flush()
+
+	if metadata.Summary == nil {
+		metadata.Summary = magicSummary
+	}
+
+	return &metadata, scanner.Err()
+}
+
+// set assigns a value given as a 'key: value' line. Lists may be written as
+// '[a, b]', 'a, b', or one item per line.
+func (m *moduleMetadata) set(key, value string, item bool) {
+	var list []string
+	if item {
+		list = []string{unquote(value)}
+	} else {
+		for _, s := range strings.Split(strings.Trim(value, "[]"), ",") {
+			if s = unquote(strings.TrimSpace(s)); s != "" {
+				list = append(list, s)
+			}
+		}
+	}
+
+	switch key {
+	case "summary":
+		summary := unquote(value)
+		m.Summary = &summary
+	case "description":
+		m.Description = unquote(value)
+	case "defaultCommand":
+		m.DefaultCommand = unquote(value)
+	case "aliases":
+		m.Aliases = append(m.Aliases, list...)
+	case "hidden":
+		m.Hidden = append(m.Hidden, list...)
+	case "order":
+		m.Order = append(m.Order, list...)
+	}
+}
+
+// dedent joins the lines of a block, removing the indentation they share and
+// any trailing blank lines.
+func dedent(lines []string) string {
+	indent := -1
+	for _, line := range lines {
+		if n := len(line) - len(strings.TrimLeft(line, " ")); line != "" && (indent < 0 || n < indent) {
+			indent = n
+		}
+	}
+
+	var b strings.Builder
+	for _, line := range lines {
+		if line != "" {
+			line = line[indent:]
+		}
+		b.WriteString(line + "\n")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
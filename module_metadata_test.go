@@ -0,0 +1,48 @@
+package exoskeleton
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseModuleMetadata(t *testing.T) {
+	summary := "Summary"
+
+	scenarios := []struct {
+		content  string
+		expected *moduleMetadata
+	}{
+		{``, &moduleMetadata{}},
+		{"# SUMMARY: Summary\n", &moduleMetadata{Summary: &summary}},
+		{
+			`{"summary": "Summary", "aliases": ["a"], "defaultCommand": "b", "hidden": ["c"], "order": ["d", "e"]}`,
+			&moduleMetadata{Summary: &summary, Aliases: []string{"a"}, DefaultCommand: "b", Hidden: []string{"c"}, Order: []string{"d", "e"}},
+		},
+		{
+			"summary: \"Summary\"\naliases: a, b # comment\nhidden: [c]\norder:\n  - d\n  - 'e'\ndefaultCommand: d\n",
+			&moduleMetadata{Summary: &summary, Aliases: []string{"a", "b"}, DefaultCommand: "d", Hidden: []string{"c"}, Order: []string{"d", "e"}},
+		},
+		{
+			"# SUMMARY: Ignored\nsummary: Summary\ndescription: One line\n",
+			&moduleMetadata{Summary: &summary, Description: "One line"},
+		},
+		{
+			"description: |\n  Line 1\n\n  Line 2\n\nsummary: Summary\n",
+			&moduleMetadata{Summary: &summary, Description: "Line 1\n\nLine 2"},
+		},
+		{
+			"description: |\n  Steps:\n  - migrate\n    - then deploy\nsummary: Summary\n",
+			&moduleMetadata{Summary: &summary, Description: "Steps:\n- migrate\n  - then deploy"},
+		},
+	}
+
+	for _, s := range scenarios {
+		metadata, err := parseModuleMetadata([]byte(s.content))
+		assert.NoError(t, err, "Given content=%q", s.content)
+		assert.Equal(t, s.expected, metadata, "Given content=%q", s.content)
+	}
+
+	_, err := parseModuleMetadata([]byte(`{"summary": `))
+	assert.Error(t, err)
+}
@@ -1,6 +1,10 @@
 package exoskeleton
 
 import (
+	"context"
+	"io"
+	"io/fs"
+	"log/slog"
 	"text/template"
 
 	"github.com/square/exoskeleton/v2/pkg/shellcomp"
@@ -17,16 +21,24 @@ type optionFunc func(*Entrypoint)
 // ExecFunc is called when an built-in command is run.
 type ExecFunc func(e *Entrypoint, args, env []string) error
 
+// ExecContextFunc is called when a built-in command is run with a context
+// (e.g. by ExecCommand).
+type ExecContextFunc func(ctx context.Context, e *Entrypoint, args, env []string) error
+
 // CompleteFunc is called when an built-in command is asked to supply shell completions.
 type CompleteFunc func(e *Entrypoint, args, env []string) ([]string, shellcomp.Directive, error)
 
 // EmbeddedCommand defines a built-in command that can be added to an Entrypoint
 // (as opposed to an executable external to the Entrypoint).
+//
+// ExecContext is optional. When it is set, it is used instead of Exec when
+// the command is run with a context.
 type EmbeddedCommand struct {
 	Name           string
 	Summary        string
 	Help           string
 	Exec           ExecFunc
+	ExecContext    ExecContextFunc
 	Complete       CompleteFunc
 	Commands       []*EmbeddedCommand
 	DefaultCommand string
@@ -91,6 +103,74 @@ func AfterIdentify(fn AfterIdentifyFunc) Option {
 	return (optionFunc)(func(e *Entrypoint) { e.afterIdentifyCallbacks = append(e.afterIdentifyCallbacks, fn) })
 }
 
+// BeforeExec registers a callback (BeforeExecFunc) to be invoked before any
+// command is executed with ExecCommand (or by Exec), including embedded
+// commands and modules (which print their menu).
+func BeforeExec(fn BeforeExecFunc) Option {
+	return (optionFunc)(func(e *Entrypoint) { e.beforeExecCallbacks = append(e.beforeExecCallbacks, fn) })
+}
+
+// AfterExec registers a callback (AfterExecFunc) to be invoked after any command
+// is executed with ExecCommand (or by Exec) with when it started, how long it
+// ran, and the error it returned.
+// (Subcommands executed with ExecCommand don't replace the entrypoint's process
+// when AfterExec callbacks are registered; see ReplaceProcess.)
+func AfterExec(fn AfterExecFunc) Option {
+	return (optionFunc)(func(e *Entrypoint) { e.afterExecCallbacks = append(e.afterExecCallbacks, fn) })
+}
+
+// WithLogger sets the logger used to trace discovery (which contracts were tried
+// for each file and why they didn't apply), identification, FileCache hits and
+// misses, and the commands that are executed. Messages are logged at the debug
+// level. (Default: a logger that writes to standard error if EXOSKELETON_LOG is
+// set and otherwise discards messages; see LogEnvVar.)
+func WithLogger(logger *slog.Logger) Option {
+	return (optionFunc)(func(e *Entrypoint) {
+		if logger == nil {
+			logger = discardLogger
+		}
+		e.logger = logger
+	})
+}
+
+// WithStdin sets the reader that executed subcommands read standard input from.
+// (Default: os.Stdin)
+//
+// When r isn't an *os.File, input is copied to the subcommand through a pipe
+// until it exits, without waiting for r to reach EOF.
+func WithStdin(r io.Reader) Option {
+	return (optionFunc)(func(e *Entrypoint) { e.stdin = r })
+}
+
+// WithStdout sets the writer that built-in commands (like help, which, and
+// complete) and menus write to and that executed subcommands write standard
+// output to. Embedded commands can find it with Entrypoint.Stdout.
+// (Default: os.Stdout)
+func WithStdout(w io.Writer) Option {
+	return (optionFunc)(func(e *Entrypoint) { e.stdout = w })
+}
+
+// WithStderr sets the writer that warnings (like "no such command") and errors
+// are written to, that executed subcommands write standard error to, and that
+// EXOSKELETON_LOG writes to. Embedded commands can find it with Entrypoint.Stderr.
+// (Default: os.Stderr)
+func WithStderr(w io.Writer) Option {
+	return (optionFunc)(func(e *Entrypoint) { e.stderr = w })
+}
+
+// WithColor determines whether menus and help are styled with colors and bold
+// text (see Theme). By default (ColorAuto), they are styled when standard output
+// is a terminal and NO_COLOR isn't set.
+func WithColor(mode ColorMode) Option {
+	return (optionFunc)(func(e *Entrypoint) { e.color = mode })
+}
+
+// WithTheme sets the Theme used to style menus and help when they are styled
+// (see WithColor). (Default: DefaultTheme)
+func WithTheme(theme Theme) Option {
+	return (optionFunc)(func(e *Entrypoint) { e.customTheme = &theme })
+}
+
 // WithName sets the name of the entrypoint.
 // (By default, this is the basename of the executable.)
 func WithName(value string) Option {
@@ -121,8 +201,73 @@ func WithMenuTemplate(value *template.Template) Option {
 
 // WithExecutor supplies a function that executes a subcommand.
 // The default executor calls `Run()` on the command and returns the error.
+// While a subcommand it executes runs, signals that the entrypoint receives
+// (like SIGTERM and SIGHUP) are forwarded to the subcommand; a custom executor
+// is responsible for forwarding them itself.
 func WithExecutor(value ExecutorFunc) Option {
-	return (optionFunc)(func(e *Entrypoint) { e.executor = value })
+	return (optionFunc)(func(e *Entrypoint) {
+		e.executor = value
+		e.customExecutor = true
+	})
+}
+
+// RunInProcessGroup places each subcommand that is executed in its own process
+// group. Signals the entrypoint receives (including SIGINT from Ctrl-C) are
+// forwarded to the whole group, so processes the subcommand starts receive
+// them too.
+//
+// The subcommand's group is not the terminal's foreground process group, so
+// avoid this option for interactive subcommands: reading from the terminal
+// would stop them. (Unix only)
+func RunInProcessGroup() Option {
+	return (optionFunc)(func(e *Entrypoint) { e.processGroup = true })
+}
+
+// ReplaceProcess makes executable subcommands replace the entrypoint's process
+// (using execve) rather than run as its child, so that supervisors see the
+// subcommand's PID and signals are delivered straight to it. AfterIdentify and
+// BeforeExec callbacks run before the process is replaced; nothing runs after it.
+//
+// Subcommands are run as children instead when an executor is supplied with
+// WithExecutor, when AfterExec callbacks will run after them (see ExecCommand),
+// when RunInProcessGroup is used, when standard input, output, or error are
+// redirected (see WithStdin), when they are executed with a context that can be
+// canceled, or on platforms other than Linux.
+func ReplaceProcess() Option {
+	return (optionFunc)(func(e *Entrypoint) { e.replaceProcess = true })
+}
+
+// WithDoctor adds the built-in 'doctor' command, which asks every command for its
+// summary, help, subcommands, and completions and reports the commands that fail
+// to respond, respond slowly, or share a name with another command. It has the
+// lowest precedence of any command.
+func WithDoctor() Option {
+	return (optionFunc)(func(e *Entrypoint) { e.includeDoctor = true })
+}
+
+// WithConcurrency sets the maximum number of commands that may run at once to
+// obtain metadata (with --summary, --help, --describe-commands, --help-opencli,
+// or --complete). Building menus and expanding modules ask commands for their
+// summaries and subcommands in parallel; this bounds the number of child
+// processes they start. Executing a command isn't limited. A value less than 1
+// removes the limit. (Default: 4×GOMAXPROCS)
+func WithConcurrency(n int) Option {
+	return (optionFunc)(func(e *Entrypoint) { e.concurrency = n })
+}
+
+// WithTimeouts limits how long Exoskeleton waits for commands to respond when it
+// executes them to obtain metadata (their summary, help, subcommands, or
+// completions). Menus list commands that don't respond in time as "(unavailable)",
+// and they have no completions. (Default: no limits)
+func WithTimeouts(t Timeouts) Option {
+	return (optionFunc)(func(e *Entrypoint) { e.timeouts = t })
+}
+
+// WithEnvPrefix sets the prefix of the variables that describe a subcommand to the
+// process that executes it (like EXOSKELETON_COMMAND_USAGE). An empty prefix
+// prevents the variables from being set. (Default: "EXOSKELETON")
+func WithEnvPrefix(value string) Option {
+	return (optionFunc)(func(e *Entrypoint) { e.envPrefix = value })
 }
 
 // WithModuleMetadataFilename sets the filename to use for module metadata.
@@ -136,9 +281,10 @@ func WithModuleMetadataFilename(value string) Option {
 //
 // The default contracts are:
 //  1. DirectoryContract (directories that contain the module metadata file)
-//  2. ExecutableContract (executables with .exoskeleton extension which must implement --describe-commands)
-//  3. ShellScriptContract (shell scripts with magic comments)
-//  4. StandaloneExecutableContract (all other executables which must implement --summary)
+//  2. SidecarContract (executables described by a sidecar file like 'foo.exoskeleton.json')
+//  3. ExecutableContract (executables with .exoskeleton extension which must implement --describe-commands)
+//  4. ShellScriptContract (shell scripts with magic comments)
+//  5. StandaloneExecutableContract (all other executables which must implement --summary)
 func WithContracts(contracts ...Contract) Option {
 	return (optionFunc)(func(e *Entrypoint) { e.contracts = contracts })
 }
@@ -148,3 +294,42 @@ func WithContracts(contracts ...Contract) Option {
 func WithCache(c Cache) Option {
 	return (optionFunc)(func(e *Entrypoint) { e.cache = c })
 }
+
+// SearchPATH makes the Entrypoint also discover commands among the executables on
+// $PATH whose names begin with the Entrypoint's name followed by a hyphen. (Like git,
+// an executable named `myapp-foo` becomes the command `myapp foo`.) Each executable
+// must fulfill one of the Entrypoint's contracts.
+//
+// Commands are listed in order of precedence:
+//  1. commands added by PrependCommands (including help, which, and complete)
+//  2. commands discovered in the paths given to New
+//  3. commands discovered in the paths given to WithFS
+//  4. commands discovered on $PATH (in the order of its directories)
+//  5. commands added by AppendCommands
+//
+// When more than one command has the same name, the first one is used. Run
+// `which --all <command>` to list them all.
+func SearchPATH() Option {
+	return (optionFunc)(func(e *Entrypoint) { e.searchPATH = true })
+}
+
+// WithFS adds paths within the given file system (such as an embed.FS) to the paths
+// searched for commands. They are searched after the paths given to New, so commands
+// on disk take precedence over commands with the same name in fsys.
+//
+// Contracts read files through DiscoveryContext.FS(). Executables found in fsys
+// are extracted to the user's cache directory when they are executed.
+//
+// Files in file systems that don't record permissions (like embed.FS, whose
+// files are all read-only) are treated as executable if they begin with a
+// shebang (#!) or are binaries, except for hidden files.
+func WithFS(fsys fs.FS, paths ...string) Option {
+	return (optionFunc)(func(e *Entrypoint) {
+		if len(paths) == 0 {
+			paths = []string{"."}
+		}
+		for _, path := range paths {
+			e.fsSources = append(e.fsSources, fsSource{fsys: fsys, path: path})
+		}
+	})
+}
@@ -0,0 +1,120 @@
+// Package exoskeletontest provides assertions for testing that an executable
+// fulfills the contract Exoskeleton expects of subcommands:
+//
+//	func TestContract(t *testing.T) {
+//		exoskeletontest.AssertContract(t, "./bin/deploy")
+//	}
+//
+// Executables are run and their output is parsed the same way an Entrypoint
+// runs and parses them.
+//
+// It also provides a Harness for testing applications built with Exoskeleton,
+// which runs an Entrypoint with the given arguments and captures its output
+// and exit code, and AssertGolden for comparing menus and help to golden files.
+package exoskeletontest
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/square/exoskeleton/v2"
+)
+
+// executableModuleExtension is the extension of executables that ExecutableContract
+// treats as modules.
+const executableModuleExtension = ".exoskeleton"
+
+// AssertContract asserts that the executable at path fulfills the contract for
+// its kind of command: executable modules (whose names end with .exoskeleton)
+// must respond to --describe-commands; other executables must respond to
+// --summary, --help, and --complete (as though <TAB> were pressed after the
+// command). It returns true if they do.
+//
+// Shell scripts that use magic comments (see exoskeleton.ShellScriptContract) aren't
+// required to respond to these flags; don't use AssertContract to test them.
+// Nor is AssertContract the test for executables that implement
+// exoskeleton.OpenCLIContract, which can't be told apart by their names: it
+// never executes them with --help-opencli. Use AssertHelpOpenCLI instead.
+func AssertContract(t testing.TB, path string) bool {
+	t.Helper()
+
+	if filepath.Ext(path) == executableModuleExtension {
+		return AssertDescribeCommands(t, path)
+	}
+
+	ok := AssertSummary(t, path)
+	ok = AssertHelp(t, path) && ok
+	ok = AssertComplete(t, path, "") && ok
+	return ok
+}
+
+// AssertSummary asserts that the executable at path responds to --summary
+// successfully with a single line. It returns true if it does.
+func AssertSummary(t testing.TB, path string) bool {
+	t.Helper()
+
+	summary, err := exoskeleton.NewContractProbe(path).Summary()
+	if err != nil {
+		t.Errorf("%s does not respond to --summary: %s", path, err)
+		return false
+	} else if summary == "" {
+		t.Errorf("%s responds to --summary with nothing (it will be hidden from menus)", path)
+		return false
+	} else if strings.Contains(summary, "\n") {
+		t.Errorf("%s responds to --summary with more than one line:\n%s", path, summary)
+		return false
+	}
+	return true
+}
+
+// AssertHelp asserts that the executable at path responds to --help
+// successfully. It returns true if it does.
+func AssertHelp(t testing.TB, path string) bool {
+	t.Helper()
+
+	if _, err := exoskeleton.NewContractProbe(path).Help(); err != nil {
+		t.Errorf("%s does not respond to --help: %s", path, err)
+		return false
+	}
+	return true
+}
+
+// AssertComplete asserts that the executable at path responds to
+// '--complete -- <args>' successfully with output that shellcomp.Unmarshal can
+// parse. It returns true if it does.
+func AssertComplete(t testing.TB, path string, args ...string) bool {
+	t.Helper()
+
+	if _, _, err := exoskeleton.NewContractProbe(path).Complete(args...); err != nil {
+		t.Errorf("%s does not respond to --complete -- %s: %s", path, strings.Join(args, " "), err)
+		return false
+	}
+	return true
+}
+
+// AssertDescribeCommands asserts that the executable at path responds to
+// --describe-commands successfully with JSON describing its subcommands.
+// It returns true if it does.
+func AssertDescribeCommands(t testing.TB, path string) bool {
+	t.Helper()
+
+	if err := exoskeleton.NewContractProbe(path).DescribeCommands(); err != nil {
+		t.Errorf("%s does not respond to --describe-commands: %s", path, err)
+		return false
+	}
+	return true
+}
+
+// AssertHelpOpenCLI asserts that the executable at path responds to
+// --help-opencli successfully with an OpenCLI document (as OpenCLIContract
+// requires). It returns true if it does.
+func AssertHelpOpenCLI(t testing.TB, path string) bool {
+	t.Helper()
+
+	if err := exoskeleton.NewContractProbe(path).HelpOpenCLI(); err != nil {
+		t.Errorf("%s does not respond to --help-opencli: %s", path, err)
+		return false
+	}
+	return true
+}
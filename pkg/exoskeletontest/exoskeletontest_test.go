@@ -0,0 +1,111 @@
+package exoskeletontest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder is a testing.TB that records failures instead of reporting them.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func write(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0755))
+	return path
+}
+
+func TestAssertContract(t *testing.T) {
+	conforming := write(t, "deploy", `#!/bin/sh
+case "$1" in
+  --summary) echo "Deploys a service" ;;
+  --help) echo "USAGE: deploy <service>" ;;
+  --complete) printf "api\nweb\n:4\n" ;;
+esac
+`)
+
+	r := &recorder{TB: t}
+	assert.True(t, AssertContract(r, conforming))
+	assert.Empty(t, r.errors)
+
+	nonconforming := write(t, "broken", `#!/bin/sh
+case "$1" in
+  --summary) printf "Two\nlines\n" ;;
+  --help) exit 1 ;;
+  --complete) echo "not completions" ;;
+esac
+`)
+
+	r = &recorder{TB: t}
+	assert.False(t, AssertContract(r, nonconforming))
+	if assert.Len(t, r.errors, 3) {
+		assert.Contains(t, r.errors[0], "responds to --summary with more than one line")
+		assert.Contains(t, r.errors[1], "does not respond to --help")
+		assert.Contains(t, r.errors[2], "does not respond to --complete")
+	}
+}
+
+func TestAssertSummary(t *testing.T) {
+	r := &recorder{TB: t}
+	assert.True(t, AssertSummary(r, "../../fixtures/hello"))
+	assert.Empty(t, r.errors)
+
+	r = &recorder{TB: t}
+	assert.False(t, AssertSummary(r, write(t, "silent", "#!/bin/sh\n")))
+	if assert.Len(t, r.errors, 1) {
+		assert.Contains(t, r.errors[0], "responds to --summary with nothing")
+	}
+}
+
+func TestAssertDescribeCommands(t *testing.T) {
+	r := &recorder{TB: t}
+	assert.True(t, AssertContract(r, "../../fixtures/go.exoskeleton"))
+	assert.Empty(t, r.errors)
+
+	for name, out := range map[string]string{
+		"invalid JSON": `{`,
+		"null":         `null`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := write(t, "mod.exoskeleton", fmt.Sprintf("#!/bin/sh\necho '%s'\n", out))
+
+			r := &recorder{TB: t}
+			assert.False(t, AssertContract(r, path))
+			if assert.Len(t, r.errors, 1) {
+				assert.Contains(t, r.errors[0], "does not respond to --describe-commands")
+			}
+		})
+	}
+
+	// Discovery accepts subcommands without names, so AssertDescribeCommands does too
+	r = &recorder{TB: t}
+	assert.True(t, AssertDescribeCommands(r, write(t, "unnamed.exoskeleton", `#!/bin/sh
+echo '{"commands": [{"name": "mod", "commands": [{}]}]}'
+`)))
+	assert.Empty(t, r.errors)
+}
+
+func TestAssertHelpOpenCLI(t *testing.T) {
+	r := &recorder{TB: t}
+	assert.True(t, AssertHelpOpenCLI(r, "../../fixtures/opencli-tool"))
+	assert.Empty(t, r.errors)
+
+	r = &recorder{TB: t}
+	assert.False(t, AssertHelpOpenCLI(r, "../../fixtures/hello"))
+	if assert.Len(t, r.errors, 1) {
+		assert.Contains(t, r.errors[0], "does not respond to --help-opencli")
+	}
+}
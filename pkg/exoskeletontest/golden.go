@@ -0,0 +1,74 @@
+package exoskeletontest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// UpdateGoldenEnvVar is the environment variable which, when set, makes
+// AssertGolden write golden files instead of comparing output to them:
+//
+//	EXOSKELETONTEST_UPDATE=1 go test ./...
+const UpdateGoldenEnvVar = "EXOSKELETONTEST_UPDATE"
+
+// AssertGolden asserts that actual matches the content of the golden file at
+// path (conventionally under testdata/). It returns true if it does. When
+// UpdateGoldenEnvVar is set, it writes actual to the file instead.
+func AssertGolden(t testing.TB, path, actual string) bool {
+	t.Helper()
+
+	if os.Getenv(UpdateGoldenEnvVar) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(actual), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return true
+	}
+
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Errorf("%s (set %s=1 to create it)", err, UpdateGoldenEnvVar)
+		return false
+	}
+
+	if string(expected) != actual {
+		line := firstDifference(string(expected), actual)
+		t.Errorf("output does not match %s (set %s=1 to update it)\nfirst difference on line %d\n--- expected\n%s\n--- actual\n%s",
+			path, UpdateGoldenEnvVar, line, expected, actual)
+		return false
+	}
+	return true
+}
+
+// AssertGolden runs the Harness's Entrypoint with args (see Run) and asserts
+// that what it writes to standard output matches the golden file at path. It
+// is meant for menus and help screens:
+//
+//	h.AssertGolden("testdata/menu.golden")
+//	h.AssertGolden("testdata/help-deploy.golden", "help", "deploy")
+func (h *Harness) AssertGolden(path string, args ...string) bool {
+	h.t.Helper()
+
+	result := h.Run(args...)
+	if result.Err != nil {
+		h.t.Errorf("%s %s: %s\n%s", result.Entrypoint.Name(), strings.Join(args, " "), result.Err, result.Stderr)
+		return false
+	}
+	return AssertGolden(h.t, path, result.Stdout)
+}
+
+// firstDifference returns the (1-based) number of the first line that differs
+// between a and b.
+func firstDifference(a, b string) int {
+	aLines, bLines := strings.Split(a, "\n"), strings.Split(b, "\n")
+	for i := range aLines {
+		if i >= len(bLines) || aLines[i] != bLines[i] {
+			return i + 1
+		}
+	}
+	return len(aLines) + 1
+}
@@ -0,0 +1,142 @@
+package exoskeletontest
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/square/exit"
+	"github.com/square/exoskeleton/v2"
+)
+
+// DefaultName is the name of the Entrypoints that a Harness builds (unless an
+// option given to NewHarness overrides it).
+const DefaultName = "app"
+
+// A Harness builds an Entrypoint from embedded commands and fake executables
+// (written to a temporary directory) and runs it with arguments as though they
+// were given on the command line, capturing its output and exit code:
+//
+//	h := exoskeletontest.NewHarness(t, exoskeleton.AppendCommands(versionCmd))
+//	h.Executable("deploy", "#!/bin/sh\n# SUMMARY: Deploys a service\necho deployed\n")
+//
+//	result := h.Run("deploy", "web")
+//	assert.Equal(t, 0, result.ExitCode)
+//	assert.Equal(t, "deployed\n", result.Stdout)
+//
+// Output is captured with exoskeleton.WithStdout and exoskeleton.WithStderr, so
+// embedded commands should write to Entrypoint.Stdout and Entrypoint.Stderr
+// rather than to os.Stdout and os.Stderr.
+type Harness struct {
+	t       testing.TB
+	dir     string
+	options []exoskeleton.Option
+}
+
+// Result is the outcome of running an Entrypoint with a Harness.
+type Result struct {
+	// Entrypoint is the Entrypoint that was run.
+	Entrypoint *exoskeleton.Entrypoint
+
+	// Command is the command that was identified (and executed).
+	Command exoskeleton.Command
+
+	// Stdout and Stderr are what the Entrypoint and the command it executed
+	// wrote to standard output and standard error.
+	Stdout string
+	Stderr string
+
+	// Err is the error returned by the command (or by identifying it).
+	Err error
+
+	// ExitCode is the code the entrypoint would exit with.
+	ExitCode int
+}
+
+// NewHarness returns a Harness that builds Entrypoints named DefaultName with
+// the given options. The Entrypoints search the Harness's directory (see Dir)
+// for commands.
+func NewHarness(t testing.TB, options ...exoskeleton.Option) *Harness {
+	t.Helper()
+
+	return &Harness{
+		t:       t,
+		dir:     t.TempDir(),
+		options: append([]exoskeleton.Option{exoskeleton.WithName(DefaultName)}, options...),
+	}
+}
+
+// Dir returns the directory that the Harness's Entrypoints search for commands.
+func (h *Harness) Dir() string {
+	return h.dir
+}
+
+// Executable writes an executable file with the given content to the given
+// path (relative to Dir), creating any directories it is in, and returns its
+// absolute path.
+func (h *Harness) Executable(path, content string) string {
+	h.t.Helper()
+	return h.write(path, content, 0755)
+}
+
+// File writes a file that isn't executable (like a module's .exoskeleton
+// metadata file) to the given path (relative to Dir), creating any directories
+// it is in, and returns its absolute path.
+func (h *Harness) File(path, content string) string {
+	h.t.Helper()
+	return h.write(path, content, 0644)
+}
+
+func (h *Harness) write(path, content string, mode os.FileMode) string {
+	h.t.Helper()
+
+	path = filepath.Join(h.dir, path)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		h.t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), mode); err != nil {
+		h.t.Fatal(err)
+	}
+	return path
+}
+
+// Entrypoint builds an Entrypoint that discovers the commands currently in Dir
+// with the Harness's options followed by the given options.
+func (h *Harness) Entrypoint(options ...exoskeleton.Option) *exoskeleton.Entrypoint {
+	h.t.Helper()
+
+	e, err := exoskeleton.New([]string{h.dir}, append(append([]exoskeleton.Option{}, h.options...), options...)...)
+	if err != nil {
+		h.t.Fatal(err)
+	}
+	return e
+}
+
+// Run builds an Entrypoint, identifies the command that args invoke, and
+// executes it (as exoskeleton.Exec does with os.Args[1:]), returning what it
+// wrote and the code it would have exited with. Commands never replace the
+// test's process (see exoskeleton.ReplaceProcess).
+func (h *Harness) Run(args ...string) Result {
+	h.t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	e := h.Entrypoint(exoskeleton.WithStdout(&stdout), exoskeleton.WithStderr(&stderr))
+
+	// A context that can be canceled prevents commands from replacing the process
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	result := Result{Entrypoint: e}
+	cmd, rest, err := e.IdentifyContext(ctx, args)
+	if err == nil {
+		result.Command = cmd
+		err = exoskeleton.ExecCommand(ctx, cmd, e, rest, os.Environ())
+	}
+
+	result.Stdout, result.Stderr = stdout.String(), stderr.String()
+	result.Err = err
+	result.ExitCode = exit.FromError(err)
+	return result
+}
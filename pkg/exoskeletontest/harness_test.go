@@ -0,0 +1,100 @@
+package exoskeletontest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/square/exit"
+	"github.com/square/exoskeleton/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestHarness(t *testing.T) *Harness {
+	h := NewHarness(t, exoskeleton.AppendCommands(&exoskeleton.EmbeddedCommand{
+		Name:    "version",
+		Summary: "Prints the version",
+		Help:    "USAGE\n   app version",
+		Exec: func(e *exoskeleton.Entrypoint, _, _ []string) error {
+			fmt.Fprintln(e.Stdout(), "1.0.0")
+			return nil
+		},
+	}))
+	h.Executable("deploy", `#!/bin/sh
+# SUMMARY: Deploys a service
+# HELP: Deploys <service> to production.
+[ -n "$1" ] || { echo "missing service" >&2; exit 2; }
+echo "deployed $1"
+`)
+	h.File("db/.exoskeleton", "summary: Manages databases\n")
+	h.Executable("db/migrate", "#!/bin/sh\n# SUMMARY: Migrates the database\necho migrated\n")
+	return h
+}
+
+func TestHarnessRun(t *testing.T) {
+	h := newTestHarness(t)
+
+	result := h.Run("deploy", "web")
+	assert.NoError(t, result.Err)
+	assert.Equal(t, 0, result.ExitCode)
+	assert.Equal(t, "app deploy", exoskeleton.Usage(result.Command))
+	assert.Equal(t, "deployed web\n", result.Stdout)
+	assert.Empty(t, result.Stderr)
+
+	result = h.Run("deploy")
+	assert.Equal(t, 1, result.ExitCode)
+	assert.Equal(t, "missing service\n", result.Stderr)
+
+	result = h.Run("db", "migrate")
+	assert.Equal(t, "migrated\n", result.Stdout)
+
+	result = h.Run("version")
+	assert.Equal(t, "1.0.0\n", result.Stdout)
+
+	result = h.Run("deplyo")
+	assert.ErrorIs(t, result.Err, exit.ErrUnknownSubcommand)
+	assert.Equal(t, exit.FromError(exit.ErrUnknownSubcommand), result.ExitCode)
+	assert.Contains(t, result.Stderr, "app: no such command deplyo")
+	assert.Contains(t, result.Stderr, "Did you mean?")
+}
+
+func TestHarnessGolden(t *testing.T) {
+	h := newTestHarness(t)
+
+	h.AssertGolden("testdata/menu.golden")
+	h.AssertGolden("testdata/menu-all.golden", "--all")
+	h.AssertGolden("testdata/help-deploy.golden", "help", "deploy")
+
+	r := &recorder{TB: t}
+	h = NewHarness(r, exoskeleton.WithName("tool"))
+	assert.False(t, h.AssertGolden("testdata/menu.golden", "nope"))
+	if assert.Len(t, r.errors, 1) {
+		assert.Contains(t, r.errors[0], "tool nope: ")
+	}
+}
+
+func TestAssertGolden(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "testdata", "output.golden")
+
+	r := &recorder{TB: t}
+	assert.False(t, AssertGolden(r, path, "hello\n"))
+	if assert.Len(t, r.errors, 1) {
+		assert.Contains(t, r.errors[0], "set EXOSKELETONTEST_UPDATE=1 to create it")
+	}
+
+	t.Setenv(UpdateGoldenEnvVar, "1")
+	assert.True(t, AssertGolden(t, path, "hello\nworld\n"))
+	contents, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello\nworld\n", string(contents))
+
+	t.Setenv(UpdateGoldenEnvVar, "")
+	assert.True(t, AssertGolden(t, path, "hello\nworld\n"))
+
+	r = &recorder{TB: t}
+	assert.False(t, AssertGolden(r, path, "hello\nthere\n"))
+	if assert.Len(t, r.errors, 1) {
+		assert.Contains(t, r.errors[0], "first difference on line 2")
+	}
+}
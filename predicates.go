@@ -11,3 +11,19 @@ func IsNull(command Command) bool {
 	_, ok := command.(nullCommand)
 	return ok
 }
+
+// IsHidden returns true if the given Command is hidden from menus and completions,
+// either by itself or by its module.
+func IsHidden(command Command) bool {
+	if h, ok := command.(HiddenReporter); ok && h.Hidden() {
+		return true
+	}
+	p, ok := command.Parent().(subcommandHider)
+	return ok && p.hidesSubcommand(command)
+}
+
+// subcommandHider is implemented by Commands that can hide their subcommands
+// (e.g. modules whose metadata lists hidden children).
+type subcommandHider interface {
+	hidesSubcommand(Command) bool
+}
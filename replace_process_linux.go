@@ -0,0 +1,22 @@
+package exoskeleton
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// replaceProcess replaces the entrypoint's process with the command using
+// execve(2). It only returns if the command can't be executed.
+func replaceProcess(cmd *exec.Cmd) error {
+	if cmd.Err != nil {
+		return cmd.Err
+	}
+
+	env := cmd.Env
+	if env == nil {
+		env = os.Environ()
+	}
+
+	return syscall.Exec(cmd.Path, cmd.Args, env)
+}
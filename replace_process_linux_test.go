@@ -0,0 +1,94 @@
+package exoskeleton
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestReplaceProcessHelper is executed by TestReplaceProcess in a child process
+// whose process is replaced by the command named by EXOSKELETON_TEST_REPLACE.
+func TestReplaceProcessHelper(t *testing.T) {
+	path := os.Getenv("EXOSKELETON_TEST_REPLACE")
+	if path == "" {
+		t.Skip("run by TestReplaceProcess")
+	}
+
+	options := []Option{WithName("e"), ReplaceProcess()}
+	if os.Getenv("EXOSKELETON_TEST_AFTER_EXEC") != "" {
+		// AfterExec callbacks aren't invoked when Exec is called directly
+		options = append(options, AfterExec(func(*Entrypoint, Command, []string, ExecResult) {}))
+	}
+
+	entrypoint, err := New([]string{filepath.Dir(path)}, options...)
+	require.NoError(t, err)
+
+	err = entrypoint.cmds.Find(filepath.Base(path)).Exec(entrypoint, nil, os.Environ())
+	t.Fatalf("should have replaced the process: %v", err)
+}
+
+func TestReplaceProcess(t *testing.T) {
+	dir := t.TempDir()
+	pidof := filepath.Join(dir, "pidof")
+	writeScript(t, pidof, "#!/bin/sh\necho \"$$\"\n")
+
+	t.Run("replaces the entrypoint", func(t *testing.T) {
+		var stdout strings.Builder
+		c := exec.Command(os.Args[0], "-test.run=^TestReplaceProcessHelper$")
+		c.Env = append(os.Environ(), "EXOSKELETON_TEST_REPLACE="+pidof)
+		c.Stdout = &stdout
+		require.NoError(t, c.Run())
+
+		assert.Equal(t, strconv.Itoa(c.Process.Pid), strings.TrimSpace(stdout.String()))
+	})
+
+	t.Run("replaces the entrypoint when AfterExec callbacks won't be invoked", func(t *testing.T) {
+		var stdout strings.Builder
+		c := exec.Command(os.Args[0], "-test.run=^TestReplaceProcessHelper$")
+		c.Env = append(os.Environ(), "EXOSKELETON_TEST_REPLACE="+pidof, "EXOSKELETON_TEST_AFTER_EXEC=1")
+		c.Stdout = &stdout
+		require.NoError(t, c.Run())
+
+		assert.Equal(t, strconv.Itoa(c.Process.Pid), strings.TrimSpace(stdout.String()))
+	})
+
+	t.Run("runs commands executed with a context that can be canceled", func(t *testing.T) {
+		entrypoint, err := New([]string{dir}, WithName("e"), ReplaceProcess())
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		assert.NoError(t, ExecCommand(ctx, entrypoint.cmds.Find("pidof"), entrypoint, nil, nil))
+	})
+
+	t.Run("runs commands when AfterExec callbacks are registered", func(t *testing.T) {
+		var called bool
+		entrypoint, err := New([]string{dir}, WithName("e"), ReplaceProcess(),
+			AfterExec(func(*Entrypoint, Command, []string, ExecResult) { called = true }))
+		require.NoError(t, err)
+
+		assert.NoError(t, ExecCommand(context.Background(), entrypoint.cmds.Find("pidof"), entrypoint, nil, nil))
+		assert.True(t, called)
+	})
+
+	t.Run("runs commands with a custom executor", func(t *testing.T) {
+		var executed bool
+		executor := func(cmd *exec.Cmd) error {
+			executed = true
+			return cmd.Run()
+		}
+
+		entrypoint, err := New([]string{dir}, WithName("e"), ReplaceProcess(), WithExecutor(executor))
+		require.NoError(t, err)
+
+		assert.NoError(t, entrypoint.cmds.Find("pidof").Exec(entrypoint, nil, nil))
+		assert.True(t, executed)
+	})
+}
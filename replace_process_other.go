@@ -0,0 +1,14 @@
+//go:build !linux
+
+package exoskeleton
+
+import (
+	"errors"
+	"os/exec"
+)
+
+// replaceProcess is not supported on this platform. Commands are run by the
+// Entrypoint's executor instead.
+func replaceProcess(cmd *exec.Cmd) error {
+	return errors.ErrUnsupported
+}
@@ -0,0 +1,132 @@
+package exoskeleton
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSignalForwardingWithATerminalHelper is executed by
+// TestSignalForwardingWithATerminal in a child process whose controlling
+// terminal is a pseudoterminal.
+func TestSignalForwardingWithATerminalHelper(t *testing.T) {
+	dir := os.Getenv("EXOSKELETON_TEST_SIGNALS_DIR")
+	if dir == "" {
+		t.Skip("run by TestSignalForwardingWithATerminal")
+	}
+
+	if os.Getenv("EXOSKELETON_TEST_SIGNALS_BACKGROUND") != "" {
+		// Put another process group in the terminal's foreground
+		foreground := exec.Command("sleep", "60")
+		foreground.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+		require.NoError(t, foreground.Start())
+		defer foreground.Process.Kill()
+
+		pgrp := int32(foreground.Process.Pid)
+		require.NoError(t, ioctl(os.Stdin.Fd(), syscall.TIOCSPGRP, unsafe.Pointer(&pgrp)))
+	}
+
+	entrypoint, err := New([]string{dir}, WithName("e"))
+	require.NoError(t, err)
+
+	err = entrypoint.cmds.Find("trap").Exec(entrypoint, nil, nil)
+	var ee *exec.ExitError
+	if errors.As(err, &ee) {
+		os.Exit(ee.ExitCode())
+	}
+	t.Fatalf("expected the command to exit unsuccessfully, got %v", err)
+}
+
+func TestSignalForwardingWithATerminal(t *testing.T) {
+	scenarios := map[string]bool{
+		"Ctrl-C in the foreground": false,
+		"kill in the background":   true,
+	}
+
+	for name, background := range scenarios {
+		t.Run(name, func(t *testing.T) {
+			master, tty, err := openPTY()
+			if err != nil {
+				t.Skipf("can't open a pseudoterminal: %s", err)
+			}
+			defer master.Close()
+
+			dir := t.TempDir()
+			ready := filepath.Join(dir, "ready")
+			writeScript(t, filepath.Join(dir, "trap"), `#!/bin/sh
+trap 'exit 42' INT
+echo "$$" > `+ready+`
+while true; do sleep 0.1; done
+`)
+
+			c := exec.Command(os.Args[0], "-test.run=^TestSignalForwardingWithATerminalHelper$")
+			c.Env = append(os.Environ(), "EXOSKELETON_TEST_SIGNALS_DIR="+dir)
+			if background {
+				c.Env = append(c.Env, "EXOSKELETON_TEST_SIGNALS_BACKGROUND=1")
+			}
+			c.Stdin = tty
+			c.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true, Ctty: 0}
+			require.NoError(t, c.Start())
+			tty.Close()
+
+			_, err = waitForPid(ready)
+			require.NoError(t, err)
+
+			if background {
+				// Like `kill -INT` from a shell: the entrypoint must forward it
+				require.NoError(t, syscall.Kill(c.Process.Pid, syscall.SIGINT))
+			} else {
+				// The terminal signals the command itself
+				_, err = master.Write([]byte{0x03})
+				require.NoError(t, err)
+			}
+
+			err = c.Wait()
+			var ee *exec.ExitError
+			if assert.ErrorAs(t, err, &ee) {
+				assert.Equal(t, 42, ee.ExitCode(), "the command should have received SIGINT")
+			}
+		})
+	}
+}
+
+// openPTY opens a pseudoterminal, returning its master and slave (tty) ends.
+func openPTY() (*os.File, *os.File, error) {
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var unlock int32
+	var n uint32
+	if err := ioctl(master.Fd(), syscall.TIOCSPTLCK, unsafe.Pointer(&unlock)); err != nil {
+		master.Close()
+		return nil, nil, err
+	}
+	if err := ioctl(master.Fd(), syscall.TIOCGPTN, unsafe.Pointer(&n)); err != nil {
+		master.Close()
+		return nil, nil, err
+	}
+
+	tty, err := os.OpenFile(fmt.Sprintf("/dev/pts/%d", n), os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		master.Close()
+		return nil, nil, err
+	}
+	return master, tty, nil
+}
+
+func ioctl(fd, req uintptr, arg unsafe.Pointer) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, req, uintptr(arg)); errno != 0 {
+		return errno
+	}
+	return nil
+}
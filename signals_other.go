@@ -0,0 +1,17 @@
+//go:build !unix
+
+package exoskeleton
+
+import "os/exec"
+
+// defaultExecutor runs the command.
+func defaultExecutor(cmd *exec.Cmd) error { return cmd.Run() }
+
+// runForwardingSignals runs the command. (Signals aren't forwarded on this platform.)
+func runForwardingSignals(cmd *exec.Cmd) error { return cmd.Run() }
+
+// setProcessGroup does nothing on platforms without process groups.
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// signalExitCode returns err unchanged on platforms without signals.
+func signalExitCode(err error) error { return err }
@@ -0,0 +1,91 @@
+//go:build unix
+
+package exoskeleton
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+
+	"github.com/square/exit"
+)
+
+// forwardedSignals are relayed to a command that is executed (see
+// runForwardingSignals).
+var forwardedSignals = []os.Signal{
+	syscall.SIGHUP,
+	syscall.SIGINT,
+	syscall.SIGQUIT,
+	syscall.SIGTERM,
+	syscall.SIGUSR1,
+	syscall.SIGUSR2,
+}
+
+// defaultExecutor runs the command.
+func defaultExecutor(cmd *exec.Cmd) error { return cmd.Run() }
+
+// runForwardingSignals runs a command that is executed on behalf of the user
+// (as opposed to one executed to obtain metadata). While it runs, signals sent
+// to the entrypoint (e.g. SIGTERM from a job runner) are forwarded to it instead
+// of terminating the entrypoint and orphaning it.
+func runForwardingSignals(cmd *exec.Cmd) error {
+	// Subscribe before starting the command so no signal is missed
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, forwardedSignals...)
+	defer signal.Stop(signals)
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go forwardSignals(cmd, signals, done)
+
+	return cmd.Wait()
+}
+
+func forwardSignals(cmd *exec.Cmd, signals <-chan os.Signal, done <-chan struct{}) {
+	ownGroup := cmd.SysProcAttr != nil && cmd.SysProcAttr.Setpgid
+
+	for {
+		select {
+		case <-done:
+			return
+		case sig := <-signals:
+			if ownGroup {
+				// Signal every process in the command's group
+				_ = syscall.Kill(-cmd.Process.Pid, sig.(syscall.Signal))
+			} else if (sig == syscall.SIGINT || sig == syscall.SIGQUIT) && inForegroundOfTerminal() {
+				// The terminal sends SIGINT and SIGQUIT (Ctrl-C and Ctrl-\) to
+				// every process in its foreground group, so the command (which
+				// is in the entrypoint's group) has already received them.
+			} else {
+				_ = cmd.Process.Signal(sig)
+			}
+		}
+	}
+}
+
+// setProcessGroup makes the command the leader of a new process group.
+func setProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+}
+
+// signalExitCode translates the error from a command that was terminated by a
+// signal into an exit.Error with the conventional exit code 128+N (e.g. 143
+// for SIGTERM) so exit.FromError returns that code.
+func signalExitCode(err error) error {
+	var ee *exec.ExitError
+	if errors.As(err, &ee) {
+		if status, ok := ee.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+			return exit.Wrap(err, 128+int(status.Signal()))
+		}
+	}
+	return err
+}
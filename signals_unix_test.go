@@ -0,0 +1,88 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package exoskeleton
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/square/exit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignalExitCode(t *testing.T) {
+	dir := t.TempDir()
+	writeScript(t, filepath.Join(dir, "killed"), "#!/bin/sh\nkill -TERM $$\n")
+	writeScript(t, filepath.Join(dir, "failed"), "#!/bin/sh\nexit 3\n")
+
+	entrypoint, err := New([]string{dir}, WithName("e"))
+	require.NoError(t, err)
+
+	err = entrypoint.cmds.Find("killed").Exec(entrypoint, nil, nil)
+	assert.Equal(t, 128+int(syscall.SIGTERM), exit.FromError(err))
+
+	err = entrypoint.cmds.Find("failed").Exec(entrypoint, nil, nil)
+	assert.Equal(t, exit.NotOK, exit.FromError(err), "should not change exit codes of commands that weren't signaled")
+}
+
+func TestSignalForwarding(t *testing.T) {
+	for _, sig := range []syscall.Signal{syscall.SIGTERM, syscall.SIGINT} {
+		for _, processGroup := range []bool{false, true} {
+			t.Run(sig.String()+"/processGroup="+strconv.FormatBool(processGroup), func(t *testing.T) {
+				if sig == syscall.SIGINT && !processGroup && inForegroundOfTerminal() {
+					t.Skip("SIGINT is assumed to have come from the terminal")
+				}
+
+				dir := t.TempDir()
+				ready := filepath.Join(dir, "ready")
+				writeScript(t, filepath.Join(dir, "trap"), `#!/bin/sh
+trap 'exit 42' TERM INT
+echo "$$" > `+ready+`
+while true; do sleep 0.1; done
+`)
+
+				options := []Option{WithName("e")}
+				if processGroup {
+					options = append(options, RunInProcessGroup())
+				}
+				entrypoint, err := New([]string{dir}, options...)
+				require.NoError(t, err)
+
+				go func() {
+					pid, err := waitForPid(ready)
+					if !assert.NoError(t, err) {
+						return
+					}
+					if pgid, err := syscall.Getpgid(pid); assert.NoError(t, err) {
+						assert.Equal(t, processGroup, pgid == pid, "should be the leader of its process group")
+					}
+					assert.NoError(t, syscall.Kill(os.Getpid(), sig))
+				}()
+
+				err = entrypoint.cmds.Find("trap").Exec(entrypoint, nil, nil)
+				var ee *exec.ExitError
+				if assert.ErrorAs(t, err, &ee) {
+					assert.Equal(t, 42, ee.ExitCode(), "should have forwarded %s to the command", sig)
+				}
+			})
+		}
+	}
+}
+
+// waitForPid waits for a command to write its pid to the file at path.
+func waitForPid(path string) (int, error) {
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if b, err := os.ReadFile(path); err == nil && strings.HasSuffix(string(b), "\n") {
+			return strconv.Atoi(strings.TrimSpace(string(b)))
+		}
+	}
+	return 0, errors.New("timed out waiting for the command to start")
+}
@@ -17,7 +17,7 @@ func (e *Entrypoint) suggestionsFor(typedName string) (suggestions []Command) {
 	fcmds, _ := cmds.Flatten()
 	for _, cmd := range fcmds {
 		usage := UsageRelativeTo(cmd, e)
-		if seen[usage] {
+		if seen[usage] || IsHidden(cmd) {
 			continue
 		}
 
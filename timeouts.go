@@ -0,0 +1,101 @@
+package exoskeleton
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"time"
+)
+
+// Timeouts limit how long Exoskeleton waits for a command that it executes to
+// obtain metadata. A command that doesn't respond in time is killed and a
+// CommandTimeoutError is returned. A zero value means no limit.
+type Timeouts struct {
+	// Summary limits how long a command may take to respond to --summary.
+	Summary time.Duration
+
+	// Help limits how long a command may take to respond to --help.
+	Help time.Duration
+
+	// Describe limits how long an executable module may take to respond to
+	// --describe-commands or --help-opencli.
+	Describe time.Duration
+
+	// Complete limits how long a command may take to respond to --complete.
+	Complete time.Duration
+}
+
+// CommandTimeoutError indicates that a command did not respond to a request for
+// metadata (like --summary) within the time allowed by the Entrypoint's Timeouts.
+type CommandTimeoutError struct {
+	CommandError
+	Timeout time.Duration
+}
+
+// waitDelay is how long to wait for a killed command's output to be closed
+// (e.g. by any processes it started) before giving up on it.
+const waitDelay = 100 * time.Millisecond
+
+func (t Timeouts) forOperation(operation string) time.Duration {
+	switch operation {
+	case "summary":
+		return t.Summary
+	case "help":
+		return t.Help
+	case "describe":
+		return t.Describe
+	case "complete":
+		return t.Complete
+	default:
+		return 0
+	}
+}
+
+// timeoutsFor returns the Timeouts of the Entrypoint at the root of the given
+// Command's tree (or no timeouts if there isn't one).
+func timeoutsFor(cmd Command) Timeouts {
+	if e := entrypointOf(cmd); e != nil {
+		return e.timeouts
+	}
+	return Timeouts{}
+}
+
+// metadataOutput executes the command with the given arguments and environment
+// to obtain metadata (e.g. its summary) and returns the exec.Cmd it ran and its
+// output. The command is killed if ctx is done or if it takes longer than the
+// timeout configured for the given operation ("summary", "help", "describe", or
+// "complete"). The timeout starts once the command may run (see WithConcurrency).
+func (cmd *executableCommand) metadataOutput(ctx context.Context, operation string, env []string, args ...string) (*exec.Cmd, []byte, error) {
+	release, err := acquireMetadataSlot(ctx, cmd)
+	if err != nil {
+		return cmd.commandContext(ctx, args...), nil, err
+	}
+	defer release()
+
+	parent := ctx
+	timeout := timeoutsFor(cmd).forOperation(operation)
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	c := cmd.commandContext(ctx, args...)
+	c.Env = env
+
+	out, err := cmd.output(c)
+	if err != nil && parent.Err() != nil {
+		err = parent.Err() // The caller gave up; the command didn't time out
+	} else if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = CommandTimeoutError{
+			CommandError{
+				Message: fmt.Sprintf("timed out after %s", timeout),
+				Command: cmd,
+				Cause:   err,
+			},
+			timeout,
+		}
+	}
+	return c, out, err
+}
@@ -0,0 +1,72 @@
+package exoskeleton
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/square/exoskeleton/v2/pkg/shellcomp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeouts(t *testing.T) {
+	dir := t.TempDir()
+	writeScript(t, filepath.Join(dir, "slow"), "#!/bin/sh\nsleep 5\n")
+	writeScript(t, filepath.Join(dir, "fast"), "#!/bin/sh\n# SUMMARY: Responds right away\n")
+
+	var reported []error
+	entrypoint, err := New([]string{dir},
+		WithName("e"),
+		WithTimeouts(Timeouts{Summary: 100 * time.Millisecond, Complete: 100 * time.Millisecond}),
+		OnError(func(_ *Entrypoint, err error) { reported = append(reported, err) }))
+	assert.NoError(t, err)
+
+	start := time.Now()
+
+	slow := entrypoint.cmds.Find("slow")
+	_, err = slow.Summary()
+	var timeoutErr CommandTimeoutError
+	if assert.ErrorAs(t, err, &timeoutErr) {
+		assert.Equal(t, 100*time.Millisecond, timeoutErr.Timeout)
+		assert.Equal(t, "slow", timeoutErr.Command.Name())
+	}
+	assert.Contains(t, err.Error(), "timed out after 100ms")
+
+	menu, errs := MenuFor(entrypoint, &MenuOptions{Theme: &PlainTheme})
+	assert.Equal(t, `COMMANDS
+   fast  Responds right away
+   slow  (unavailable)`, sections(menu))
+	if assert.Len(t, errs, 1) {
+		assert.True(t, errors.As(errs[0], &CommandTimeoutError{}))
+	}
+
+	completions, directive, err := slow.Complete(entrypoint, []string{""}, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, completions)
+	assert.Equal(t, shellcomp.DirectiveNoFileComp, directive)
+	if assert.Len(t, reported, 1) {
+		assert.True(t, errors.As(reported[0], &CommandTimeoutError{}))
+	}
+
+	assert.Less(t, time.Since(start), 4*time.Second)
+}
+
+func TestTimeoutsDuringDiscovery(t *testing.T) {
+	dir := t.TempDir()
+	writeScript(t, filepath.Join(dir, "slow"), "#!/bin/sh\nsleep 5\n")
+
+	entrypoint, err := New([]string{dir},
+		WithName("e"),
+		WithContracts(&StandaloneExecutableContract{}),
+		WithTimeouts(Timeouts{Summary: 100 * time.Millisecond}))
+	assert.NoError(t, err)
+
+	// Executables that time out while they're discovered are listed as unavailable
+	menu, errs := MenuFor(entrypoint, &MenuOptions{Theme: &PlainTheme})
+	assert.Equal(t, `COMMANDS
+   slow  (unavailable)`, sections(menu))
+	if assert.Len(t, errs, 1) {
+		assert.True(t, errors.As(errs[0], &CommandTimeoutError{}))
+	}
+}
@@ -0,0 +1,7 @@
+//go:build unix && !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package exoskeleton
+
+// inForegroundOfTerminal returns false on platforms where the foreground process
+// group of a terminal isn't known, so SIGINT and SIGQUIT are always forwarded.
+func inForegroundOfTerminal() bool { return false }
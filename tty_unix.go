@@ -0,0 +1,35 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package exoskeleton
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// inForegroundOfTerminal returns true if the entrypoint is in the foreground
+// process group of its controlling terminal, which is the group the terminal
+// sends SIGINT and SIGQUIT to. Signals sent to entrypoints in the background or
+// without a terminal came from elsewhere (like a supervisor or kill).
+func inForegroundOfTerminal() bool {
+	tty, err := os.Open("/dev/tty")
+	if err != nil {
+		return false
+	}
+	defer tty.Close()
+
+	pgrp, err := foregroundProcessGroup(tty)
+	return err == nil && pgrp == syscall.Getpgrp()
+}
+
+// foregroundProcessGroup returns the ID of the foreground process group of the
+// given terminal (like tcgetpgrp).
+func foregroundProcessGroup(tty *os.File) (int, error) {
+	var pgrp int32
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, tty.Fd(), uintptr(syscall.TIOCGPGRP), uintptr(unsafe.Pointer(&pgrp)))
+	if errno != 0 {
+		return 0, errno
+	}
+	return int(pgrp), nil
+}
@@ -2,7 +2,6 @@ package exoskeleton
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
 
 	"github.com/square/exit"
@@ -16,16 +15,19 @@ const WhichHelp = `USAGE
    Displays the path to %[1]s for built-in commands like which and help.
 
 OPTIONS
+   -a, --all               Display the paths of every command with the given name,
+                           in order of precedence (the first is the one that runs)
    -s, --follow-symlinks   Follow symlinks before displaying the path
 
 EXAMPLES
    %[1]s which            # Display the path to %[1]s
    %[1]s which help       # Display the path to %[1]s
-   %[1]s which foobar     # Display the path to the foobar command`
+   %[1]s which foobar     # Display the path to the foobar command
+   %[1]s which -a foobar  # Display the paths to every foobar command`
 
 // WhichExec implements the 'which' command.
 func WhichExec(e *Entrypoint, args, _ []string) error {
-	identifyArgs, willResolveSymlinks := splitWhichArgs(args)
+	identifyArgs, willResolveSymlinks, willListAll := splitWhichArgs(args)
 
 	cmd, _, err := e.Identify(identifyArgs)
 	if err != nil {
@@ -34,26 +36,56 @@ func WhichExec(e *Entrypoint, args, _ []string) error {
 		return exit.ErrUnknownSubcommand
 	}
 
-	path := cmd.Path()
-	if willResolveSymlinks {
-		resolvedPath, err := filepath.EvalSymlinks(path)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "ERROR: Unable to follow symlinks in %s\n", path)
+	cmds := Commands{cmd}
+	if willListAll {
+		if cmds, err = namesakes(cmd); err != nil {
 			return err
 		}
-		path = resolvedPath
 	}
 
-	fmt.Println(path)
+	for _, cmd := range cmds {
+		path := cmd.Path()
+		if willResolveSymlinks {
+			resolvedPath, err := filepath.EvalSymlinks(path)
+			if err != nil {
+				fmt.Fprintf(e.Stderr(), "ERROR: Unable to follow symlinks in %s\n", path)
+				return err
+			}
+			path = resolvedPath
+		}
+
+		fmt.Fprintln(e.Stdout(), path)
+	}
 	return nil
 }
 
-// splitWhichArgs separates which's own --follow-symlinks/-s flags from the
-// arguments that identify the command. The flags are consumed here rather than
-// forwarded to Identify: left in, a flag becomes a trailing argument and causes
+// namesakes returns the given command along with every other command with the
+// same parent that is named (or aliased) the same thing, in order of precedence.
+func namesakes(cmd Command) (Commands, error) {
+	if cmd.Parent() == nil {
+		return Commands{cmd}, nil
+	}
+
+	siblings, err := cmd.Parent().Subcommands()
+	if err != nil && !isPartial(err) {
+		return nil, err
+	}
+
+	var cmds Commands
+	for _, sibling := range siblings {
+		if sibling == cmd || (Commands{sibling}).Find(cmd.Name()) != nil {
+			cmds = append(cmds, sibling)
+		}
+	}
+	return cmds, nil
+}
+
+// splitWhichArgs separates which's own --follow-symlinks/-s and --all/-a flags
+// from the arguments that identify the command. The flags are consumed here rather
+// than forwarded to Identify: left in, a flag becomes a trailing argument and causes
 // Identify to resolve the command's default subcommand instead of the command
 // itself. Everything after a `--` terminator is left untouched for the command.
-func splitWhichArgs(args []string) (identifyArgs []string, followSymlinks bool) {
+func splitWhichArgs(args []string) (identifyArgs []string, followSymlinks, all bool) {
 	identifyArgs = make([]string, 0, len(args))
 
 	for i, arg := range args {
@@ -62,10 +94,12 @@ func splitWhichArgs(args []string) (identifyArgs []string, followSymlinks bool)
 			break
 		} else if arg == "--follow-symlinks" || arg == "-s" {
 			followSymlinks = true
+		} else if arg == "--all" || arg == "-a" {
+			all = true
 		} else {
 			identifyArgs = append(identifyArgs, arg)
 		}
 	}
 
-	return identifyArgs, followSymlinks
+	return identifyArgs, followSymlinks, all
 }
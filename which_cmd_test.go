@@ -27,8 +27,40 @@ func TestWhichConsumesFollowSymlinksFlag(t *testing.T) {
 	}
 
 	for _, s := range scenarios {
-		identifyArgs, followSymlinks := splitWhichArgs(s.args)
+		identifyArgs, followSymlinks, _ := splitWhichArgs(s.args)
 		assert.Equal(t, s.wantIdentifyArgs, identifyArgs, "which %v", s.args)
 		assert.Equal(t, s.wantFollowSymlinks, followSymlinks, "which %v", s.args)
 	}
 }
+
+func TestWhichConsumesAllFlag(t *testing.T) {
+	scenarios := []struct {
+		args             []string
+		wantIdentifyArgs []string
+		wantAll          bool
+	}{
+		{[]string{"tool"}, []string{"tool"}, false},
+		{[]string{"tool", "-a"}, []string{"tool"}, true},
+		{[]string{"--all", "tool"}, []string{"tool"}, true},
+		{[]string{"tool", "--", "-a"}, []string{"tool", "--", "-a"}, false},
+	}
+
+	for _, s := range scenarios {
+		identifyArgs, _, all := splitWhichArgs(s.args)
+		assert.Equal(t, s.wantIdentifyArgs, identifyArgs, "which %v", s.args)
+		assert.Equal(t, s.wantAll, all, "which %v", s.args)
+	}
+}
+
+func TestNamesakes(t *testing.T) {
+	entrypoint := &Entrypoint{}
+	a := &executableCommand{parent: entrypoint, name: "a", path: "/bin/a"}
+	b := &executableCommand{parent: entrypoint, name: "b", path: "/bin/b"}
+	aliasedA := &executableCommand{parent: entrypoint, name: "z", aliases: []string{"a"}, path: "/bin/z"}
+	overloadedA := &executableCommand{parent: entrypoint, name: "a", path: "/usr/bin/a"}
+	entrypoint.cmds = Commands{a, b, aliasedA, overloadedA}
+
+	cmds, err := namesakes(a)
+	assert.NoError(t, err)
+	assert.Equal(t, Commands{a, aliasedA, overloadedA}, cmds)
+}